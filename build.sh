@@ -1 +1,4 @@
-CGO_ENABLED=0 go build -ldflags "-s -w" .
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo 0.1.0)
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)
+DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+CGO_ENABLED=0 go build -ldflags "-s -w -X ollie/cmd.version=$VERSION -X ollie/cmd.commit=$COMMIT -X ollie/cmd.date=$DATE" .
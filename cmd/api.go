@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"ollie/pkg/ollama"
+)
+
+// Media types of layers that are passed to the create API as files rather than
+// as text fields
+const (
+	adapterMediaType   = "application/vnd.ollama.image.adapter"
+	projectorMediaType = "application/vnd.ollama.image.projector"
+	messagesMediaType  = "application/vnd.ollama.image.messages"
+)
+
+// maxBufferedBlob is the largest blob kept in memory while loading via the API,
+// enough for templates, parameters, licenses, and other text layers
+const maxBufferedBlob = 1 << 20
+
+// apiClient talks to a running Ollama server. Each request carries the
+// caller's context, so --timeout and interrupts abort it.
+type apiClient struct {
+	base string
+}
+
+// blobURL returns the URL of a blob on the server, addressed by its digest
+func (c *apiClient) blobURL(digest string) string {
+	return c.base + "/api/blobs/" + digest
+}
+
+// hasBlob reports whether the server already has the blob with digest
+func (c *apiClient) hasBlob(ctx context.Context, digest string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.blobURL(digest), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach Ollama API: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if ctx.Err() != nil {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return false, contextError(ctx)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reach Ollama API: %w", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// pushBlob uploads size bytes from r as the blob with digest. The server checks
+// the content against the digest.
+func (c *apiClient) pushBlob(ctx context.Context, digest string, r io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.blobURL(digest), r)
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", digest, err)
+	}
+	req.ContentLength = size
+	resp, err := http.DefaultClient.Do(req)
+	if ctx.Err() != nil {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return contextError(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", digest, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to upload blob %s: server returned %s: %s", digest, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// createModel creates a model on the server from a create API request
+func (c *apiClient) createModel(ctx context.Context, request map[string]any) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode create request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/api/create", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create model: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if ctx.Err() != nil {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return contextError(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var status struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &status) == nil && status.Error != "" {
+		return fmt.Errorf("failed to create model %s: %s", request["model"], status.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create model %s: server returned %s", request["model"], resp.Status)
+	}
+	return nil
+}
+
+// createRequest builds a create API request for a manifest from its layers.
+// GGUF layers are referenced by digest and text layers are inlined from blobs.
+func createRequest(name string, manifest *Manifest, blobs map[string][]byte) (map[string]any, error) {
+	request := map[string]any{"model": name, "stream": false}
+	files := map[string]string{}
+	adapters := map[string]string{}
+
+	text := func(layer Layer, digest string) (string, error) {
+		data, ok := blobs[digest]
+		if !ok {
+			return "", fmt.Errorf("%s layer %s is missing from the tarball or too large to inline", layer.MediaType, digest)
+		}
+		return string(data), nil
+	}
+
+	for i, layer := range manifest.Layers {
+		// Blobs are keyed by their canonical digest, which a hand-edited
+		// manifest may not spell the same way
+		digest := ollama.NormalizeDigest(layer.Digest)
+		switch layer.MediaType {
+		case modelMediaType, projectorMediaType:
+			files[fmt.Sprintf("%s-%d.gguf", path.Ext(layer.MediaType)[1:], i)] = digest
+		case adapterMediaType:
+			adapters[fmt.Sprintf("adapter-%d.gguf", i)] = digest
+		case templateMediaType, systemMediaType, licenseMediaType:
+			value, err := text(layer, digest)
+			if err != nil {
+				return nil, err
+			}
+			request[path.Ext(layer.MediaType)[1:]] = value
+		case paramsMediaType, messagesMediaType:
+			value, err := text(layer, digest)
+			if err != nil {
+				return nil, err
+			}
+			var decoded any
+			if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+				return nil, fmt.Errorf("failed to decode %s layer: %w", layer.MediaType, err)
+			}
+			key := "parameters"
+			if layer.MediaType == messagesMediaType {
+				key = "messages"
+			}
+			request[key] = decoded
+		default:
+			slog.Warn("skipping layer the create API doesn't accept", "mediaType", layer.MediaType, "digest", layer.Digest)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("model %s has no model layer", name)
+	}
+	request["files"] = files
+	if len(adapters) > 0 {
+		request["adapters"] = adapters
+	}
+	return request, nil
+}
+
+// manifestEntryModel returns the model a manifests/host/namespace/model/tag
+// tar entry belongs to
+func manifestEntryModel(name string) (*ModelName, error) {
+	parts := strings.Split(path.Clean(name), "/")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("unexpected manifest in tarball: %s (expected manifests/host/namespace/model/tag)", name)
+	}
+	return &ModelName{Host: parts[1], Namespace: parts[2], Model: parts[3], Tag: parts[4]}, nil
+}
+
+// loadViaAPI loads a tarball into a running Ollama server instead of the models
+// directory: each blob is uploaded through the blob endpoint, then each manifest
+// is recreated through the create API. Blobs the server already has are skipped.
+//
+// expectedSum, when set, is checked against the raw input once it has been read
+// and before any model is created. Blobs may already be uploaded by then, but
+// the server checks each against its digest and nothing references them.
+func loadViaAPI(ctx context.Context, fileName, apiURL string, retag *ModelName, expectedSum string, quiet bool) error {
+	client := &apiClient{base: strings.TrimRight(apiURL, "/")}
+
+	input, size, err := openLoadSource(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	bar := newProgressBar("Uploading", size, quiet)
+	defer bar.Finish()
+	inputHasher := sha256.New()
+	br := bufio.NewReader(io.TeeReader(&contextReader{ctx: ctx, r: input}, io.MultiWriter(bar, inputHasher)))
+	decompressed, err := newDecompressReader(br, detectCompression(br))
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	tarReader := tar.NewReader(decompressed)
+
+	// Manifests and text blobs are kept until the whole tarball has been read,
+	// since --reproducible tarballs list blobs before manifests
+	type namedManifest struct {
+		name     *ModelName
+		manifest *Manifest
+	}
+	manifests := []namedManifest{}
+	blobs := map[string][]byte{}
+	uploaded, skipped := 0, 0
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := validateEntryName(header.Name, false); err != nil {
+			return err
+		}
+
+		if isManifestEntry(header.Name) {
+			var manifest Manifest
+			if err := json.NewDecoder(tarReader).Decode(&manifest); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrManifestCorrupt, header.Name, err)
+			}
+			if manifest.IsIndex() {
+				return fmt.Errorf("%s: index manifests can't be loaded via the API", header.Name)
+			}
+			name, err := manifestEntryModel(header.Name)
+			if err != nil {
+				return err
+			}
+			manifests = append(manifests, namedManifest{name: name, manifest: &manifest})
+			bar.File(header.Name)
+			continue
+		}
+
+		sum, ok := blobDigest(header.Name)
+		if !ok {
+			return fmt.Errorf("unexpected entry in tarball: %s (expected a manifest or blobs/sha256-<hex>)", header.Name)
+		}
+		digest := "sha256:" + sum
+		var body io.Reader = tarReader
+		if header.Size <= maxBufferedBlob {
+			data, err := io.ReadAll(tarReader)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+			blobs[digest] = data
+			body = bytes.NewReader(data)
+		}
+
+		exists, err := client.hasBlob(ctx, digest)
+		if err != nil {
+			return err
+		}
+		if exists {
+			skipped++
+		} else {
+			if err := client.pushBlob(ctx, digest, body, header.Size); err != nil {
+				return err
+			}
+			uploaded++
+		}
+		bar.File(header.Name)
+	}
+
+	// Like extraction, the check covers whatever follows the archive too
+	if expectedSum != "" {
+		decompressed.Close()
+		if _, err := io.Copy(io.Discard, br); err != nil {
+			return fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if actual := hex.EncodeToString(inputHasher.Sum(nil)); actual != expectedSum {
+			return fmt.Errorf("%w: expected sha256 %s, got %s", errTarballMismatch, expectedSum, actual)
+		}
+	}
+	bar.Finish()
+
+	if retag != nil {
+		if len(manifests) != 1 {
+			return fmt.Errorf("--retag requires a tarball with a single manifest, found %d", len(manifests))
+		}
+		manifests[0].name = retag
+	}
+	for _, m := range manifests {
+		request, err := createRequest(m.name.ShortString(), m.manifest, blobs)
+		if err != nil {
+			return err
+		}
+		if err := client.createModel(ctx, request); err != nil {
+			return err
+		}
+		slog.Info("Created model", "model", m.name.ShortString())
+	}
+
+	slog.Info("Uploaded blobs", "uploaded", uploaded, "present", skipped)
+	return nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOllamaServer records the API requests load --via-api makes
+type fakeOllamaServer struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *fakeOllamaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusNotFound)
+	case strings.HasPrefix(r.URL.Path, "/api/blobs/"):
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.Write([]byte(`{"status":"success"}`))
+	}
+}
+
+func TestLoadViaAPIChecksum(t *testing.T) {
+	src := newTestStore(t)
+	addDefaultTestModel(t, src, "llama2:7b")
+	tarball := saveTestTarball(t, src, compressNone, "llama2:7b")
+	sum, err := fileSHA256(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name     string
+		expected string
+		stream   bool
+		wantErr  bool
+		creates  bool
+		uploads  bool
+		canceled bool
+	}{
+		{name: "no checksum", creates: true, uploads: true},
+		{name: "file matches", expected: sum, creates: true, uploads: true},
+		{name: "file mismatch", expected: wrong, wantErr: true},
+		{name: "stream matches", expected: sum, stream: true, creates: true, uploads: true},
+		{name: "stream mismatch", expected: wrong, stream: true, wantErr: true, uploads: true},
+		{name: "canceled", wantErr: true, canceled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeOllamaServer{}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.canceled {
+				cancel()
+			}
+
+			// A file is checked up front; stdin is checked as it's read
+			fileName := tarball
+			expected := tt.expected
+			if tt.stream {
+				fileName = "-"
+				stdin, err := os.Open(tarball)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer stdin.Close()
+				saved := os.Stdin
+				os.Stdin = stdin
+				defer func() { os.Stdin = saved }()
+			}
+			expected, err := verifyTarballFile(fileName, expected)
+			if err == nil {
+				err = loadViaAPI(ctx, fileName, ts.URL, nil, expected, true)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("load via API: %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.expected == wrong && !errors.Is(err, errTarballMismatch) {
+				t.Errorf("got %v, want a checksum mismatch", err)
+			}
+			if tt.canceled && !errors.Is(err, context.Canceled) {
+				t.Errorf("got %v, want context.Canceled", err)
+			}
+
+			uploads, creates := false, false
+			for _, request := range server.requests {
+				uploads = uploads || strings.HasPrefix(request, "POST /api/blobs/")
+				creates = creates || request == "POST /api/create"
+			}
+			if uploads != tt.uploads || creates != tt.creates {
+				t.Errorf("requests %v: uploads %v, creates %v; want %v, %v", server.requests, uploads, creates, tt.uploads, tt.creates)
+			}
+		})
+	}
+}
+
+func TestAPIClientContext(t *testing.T) {
+	// The server doesn't answer until the test ends, so only the context can
+	// end each request
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+	client := &apiClient{base: ts.URL}
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"hasBlob", func(ctx context.Context) error {
+			_, err := client.hasBlob(ctx, "sha256:"+strings.Repeat("0", 64))
+			return err
+		}},
+		{"pushBlob", func(ctx context.Context) error {
+			return client.pushBlob(ctx, "sha256:"+strings.Repeat("0", 64), strings.NewReader("data"), 4)
+		}},
+		{"createModel", func(ctx context.Context) error {
+			return client.createModel(ctx, map[string]any{"model": "llama2:7b"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			if err := tt.call(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got %v, want a timeout", err)
+			}
+		})
+	}
+}
+
+func TestCreateRequestNormalizesDigests(t *testing.T) {
+	model := strings.Repeat("ab", 32)
+	template := strings.Repeat("cd", 32)
+	manifest := &Manifest{Layers: []Layer{
+		{MediaType: modelMediaType, Digest: "SHA256:" + strings.ToUpper(model)},
+		{MediaType: templateMediaType, Digest: template},
+	}}
+	blobs := map[string][]byte{"sha256:" + template: []byte("{{ .Prompt }}")}
+
+	request, err := createRequest("llama2:7b", manifest, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := request["template"]; got != "{{ .Prompt }}" {
+		t.Errorf("template = %v, want the inlined template blob", got)
+	}
+	files := request["files"].(map[string]string)
+	if got := files["model-0.gguf"]; got != "sha256:"+model {
+		t.Errorf("files = %v, want model-0.gguf to reference sha256:%s", files, model)
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// textMediaTypes are the blobs cat writes to a terminal, since they hold text
+var textMediaTypes = map[string]bool{
+	configMediaType:   true,
+	templateMediaType: true,
+	systemMediaType:   true,
+	paramsMediaType:   true,
+	licenseMediaType:  true,
+}
+
+// selectLayer picks the one layer of manifest whose media type matches pattern,
+// or its config when config is set
+func selectLayer(manifest *Manifest, pattern string, config bool) (Layer, error) {
+	if config {
+		if manifest.Config.Digest == "" {
+			return Layer{}, fmt.Errorf("manifest has no config blob")
+		}
+		return manifest.Config, nil
+	}
+
+	matches := []Layer{}
+	mediaTypes := []string{}
+	for _, layer := range manifest.Layers {
+		mediaTypes = append(mediaTypes, layer.MediaType)
+		if matchesMediaType(layer.MediaType, pattern) {
+			matches = append(matches, layer)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Layer{}, fmt.Errorf("no layer has media type %s (available: %s)", pattern, strings.Join(mediaTypes, ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		digests := []string{}
+		for _, layer := range matches {
+			digests = append(digests, layer.Digest)
+		}
+		return Layer{}, fmt.Errorf("%d layers have media type %s: %s", len(matches), pattern, strings.Join(digests, ", "))
+	}
+}
+
+var catCmd = &cobra.Command{
+	Use:   "cat MODEL_NAME",
+	Short: "Write one of a model's blobs to stdout",
+	Long: `Write the contents of one of a model's blobs to stdout, e.g. to extract its
+raw GGUF weights without working out the blob's sha256 path.
+
+Pick the layer by media type with --layer, either in full or by its last
+component (such as "model" or "template"), or use --config for the config blob.
+The layer must be unique; an index manifest's only variant is used.
+
+Like save, cat refuses to write binary data to a terminal; the config and text
+layers (template, system, params, and license) can be shown there.
+
+Examples:
+  ollie cat llama2 --layer model > llama2.gguf
+  ollie cat llama2 --layer template
+  ollie cat llama2 --config | jq .`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if catLayer == "" && !catConfig {
+			return fmt.Errorf("specify a layer with --layer MEDIATYPE or use --config")
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		resolved, err := resolveModelNames(args[0], modelPath, false)
+		if err != nil {
+			return err
+		}
+		modelName := resolved[0]
+
+		manifest, err := readManifest(filepath.Join(modelPath, manifestRelPath(modelName)))
+		if err != nil {
+			return err
+		}
+		if manifest.IsIndex() {
+			entry, err := selectVariant(manifest, "")
+			if err != nil {
+				return fmt.Errorf("%s: %w", modelName.ShortString(), err)
+			}
+			if manifest, err = readVariantManifest(modelPath, *entry); err != nil {
+				return err
+			}
+		}
+
+		layer, err := selectLayer(manifest, catLayer, catConfig)
+		if err != nil {
+			return fmt.Errorf("%s: %w", modelName.ShortString(), err)
+		}
+
+		// Text layers such as templates are fine to show, but weights aren't
+		if !textMediaTypes[layer.MediaType] && term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("refusing to write binary blob to terminal\nPlease redirect output to a file: ollie cat %s --layer %s > blob.bin", args[0], catLayer)
+		}
+
+		blob, err := os.Open(filepath.Join(modelPath, "blobs", layer.BlobName()))
+		if err != nil {
+			return fmt.Errorf("failed to open blob: %w", err)
+		}
+		defer blob.Close()
+
+		if _, err := io.Copy(os.Stdout, blob); err != nil {
+			return fmt.Errorf("failed to write blob: %w", err)
+		}
+		return nil
+	},
+}
+
+var (
+	catLayer  string
+	catConfig bool
+)
+
+func init() {
+	catCmd.Flags().StringVar(&catLayer, "layer", "", "media type of the layer to write, e.g. model or application/vnd.ollama.image.template")
+	catCmd.Flags().BoolVar(&catConfig, "config", false, "write the config blob")
+	catCmd.MarkFlagsMutuallyExclusive("layer", "config")
+	rootCmd.AddCommand(catCmd)
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/ulikunitz/xz"
+)
+
+// Supported compression formats for save
+const (
+	compressNone  = "none"
+	compressGzip  = "gzip"
+	compressXz    = "xz"
+	compressBzip2 = "bzip2"
+	compressZstd  = "zstd"
+)
+
+// compressionFromFileName infers the compression format from a tarball file name
+func compressionFromFileName(fileName string) string {
+	switch {
+	case strings.HasSuffix(fileName, ".tar.gz"), strings.HasSuffix(fileName, ".tgz"):
+		return compressGzip
+	case strings.HasSuffix(fileName, ".tar.xz"), strings.HasSuffix(fileName, ".txz"):
+		return compressXz
+	case strings.HasSuffix(fileName, ".tar.bz2"), strings.HasSuffix(fileName, ".tar.bz"), strings.HasSuffix(fileName, ".tbz2"):
+		return compressBzip2
+	case strings.HasSuffix(fileName, ".tar.zst"), strings.HasSuffix(fileName, ".tzst"):
+		return compressZstd
+	default:
+		return compressNone
+	}
+}
+
+// tarballExtension returns the file extension for a tarball compressed with format
+func tarballExtension(format string) string {
+	switch format {
+	case compressGzip:
+		return ".tar.gz"
+	case compressXz:
+		return ".tar.xz"
+	case compressBzip2:
+		return ".tar.bz2"
+	case compressZstd:
+		return ".tar.zst"
+	default:
+		return ".tar"
+	}
+}
+
+// compressionMagic maps each compression format to the magic bytes its streams start with
+var compressionMagic = []struct {
+	format string
+	magic  []byte
+}{
+	{compressGzip, []byte{0x1f, 0x8b}},
+	{compressXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{compressBzip2, []byte{'B', 'Z', 'h'}},
+	{compressZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// detectCompression peeks at the start of br to identify its compression format,
+// returning compressNone when no known magic bytes are found
+func detectCompression(br *bufio.Reader) string {
+	for _, m := range compressionMagic {
+		head, _ := br.Peek(len(m.magic))
+		if bytes.Equal(head, m.magic) {
+			return m.format
+		}
+	}
+	return compressNone
+}
+
+// newDecompressReader wraps r with a decompressor for the given format.
+// Closing the returned reader releases the decompressor but does not close r.
+func newDecompressReader(r io.Reader, format string) (io.ReadCloser, error) {
+	switch format {
+	case "", compressNone:
+		return io.NopCloser(r), nil
+	case compressGzip:
+		gzReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		return gzReader, nil
+	case compressXz:
+		xzReader, err := xz.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xz reader: %w", err)
+		}
+		return io.NopCloser(xzReader), nil
+	case compressBzip2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case compressZstd:
+		zstdReader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		return zstdReader.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression format: %s", format)
+	}
+}
+
+// gzipBlockSize is how much input each goroutine compresses at a time when
+// gzip output is compressed in parallel
+const gzipBlockSize = 1 << 20
+
+// checkCompressionLevel reports an error when level isn't valid for format.
+// A level of 0 selects the format's default and is always accepted.
+func checkCompressionLevel(format string, level int) error {
+	if level == 0 {
+		return nil
+	}
+	switch format {
+	case compressGzip, compressBzip2:
+		if level < 1 || level > 9 {
+			return fmt.Errorf("invalid %s level %d (expected 1-9)", format, level)
+		}
+	case compressZstd:
+		if level < 1 || level > 22 {
+			return fmt.Errorf("invalid zstd level %d (expected 1-22)", level)
+		}
+	case compressXz:
+		return fmt.Errorf("--level isn't supported for xz compression")
+	case "", compressNone:
+		return fmt.Errorf("--level requires compressed output (see --compress)")
+	}
+	return nil
+}
+
+// newCompressWriter wraps w with a compressor for the given format.
+// A level of 0 uses the format's default; xz has no levels. gzip and zstd
+// compress on up to threads goroutines; xz and bzip2 use one.
+// Closing the returned writer flushes the compressor but does not close w.
+func newCompressWriter(w io.Writer, format string, level, threads int) (io.WriteCloser, error) {
+	if err := checkCompressionLevel(format, level); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", compressNone:
+		return nopWriteCloser{w}, nil
+	case compressGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		if threads <= 1 {
+			gzWriter, err := gzip.NewWriterLevel(w, level)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gzip level %d: %w", level, err)
+			}
+			return gzWriter, nil
+		}
+		// pgzip compresses blocks in parallel into one ordinary gzip stream
+		gzWriter, err := pgzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip level %d: %w", level, err)
+		}
+		if err := gzWriter.SetConcurrency(gzipBlockSize, threads); err != nil {
+			return nil, fmt.Errorf("failed to set gzip concurrency: %w", err)
+		}
+		return gzWriter, nil
+	case compressXz:
+		xzWriter, err := xz.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xz writer: %w", err)
+		}
+		return xzWriter, nil
+	case compressBzip2:
+		// The standard library only ships a bzip2 decoder, so use the system tool
+		args := []string{"-c"}
+		if level != 0 {
+			args = append(args, fmt.Sprintf("-%d", level))
+		}
+		return newExecWriter(w, "bzip2", args...)
+	case compressZstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(max(threads, 1))}
+		if level != 0 {
+			// zstd's 1-22 levels map onto the encoder's four speed settings
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		zstdWriter, err := zstd.NewWriter(w, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		return zstdWriter, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression format: %s (expected none, gzip, xz, bzip2, or zstd)", format)
+	}
+}
+
+// execWriter pipes written data through an external command into an underlying writer
+type execWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// newExecWriter starts name with args, feeding it written data and sending its output to w
+func newExecWriter(w io.Writer, name string, args ...string) (io.WriteCloser, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s compression requires the %s command in PATH: %w", name, name, err)
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s pipe: %w", name, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	return &execWriter{cmd: cmd, stdin: stdin}, nil
+}
+
+func (e *execWriter) Write(p []byte) (int, error) {
+	return e.stdin.Write(p)
+}
+
+// Close closes the command's input and waits for it to finish writing
+func (e *execWriter) Close() error {
+	if err := e.stdin.Close(); err != nil {
+		return err
+	}
+	if err := e.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %w", e.cmd.Path, err)
+	}
+	return nil
+}
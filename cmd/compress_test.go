@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// benchData returns n bytes of seeded pseudo-random data that compresses
+// somewhat, like quantized weights rather than text or noise
+func benchData(n int) []byte {
+	rng := rand.New(rand.NewPCG(1, 2))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(rng.IntN(64))
+	}
+	return data
+}
+
+func TestCheckCompressionLevel(t *testing.T) {
+	tests := []struct {
+		format string
+		level  int
+		ok     bool
+	}{
+		{compressGzip, 0, true},
+		{compressGzip, 1, true},
+		{compressGzip, 9, true},
+		{compressGzip, 10, false},
+		{compressGzip, -1, false},
+		{compressBzip2, 9, true},
+		{compressBzip2, -1, false},
+		{compressZstd, 19, true},
+		{compressZstd, 22, true},
+		{compressZstd, 23, false},
+		{compressXz, 0, true},
+		{compressXz, 6, false},
+		{compressNone, 0, true},
+		{compressNone, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, tt.level), func(t *testing.T) {
+			err := checkCompressionLevel(tt.format, tt.level)
+			if (err == nil) != tt.ok {
+				t.Errorf("checkCompressionLevel(%q, %d) = %v, want ok=%v", tt.format, tt.level, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompressWriterGzipRoundTrip(t *testing.T) {
+	data := benchData(3*gzipBlockSize + 12345)
+	for _, threads := range []int{1, 4} {
+		t.Run(fmt.Sprintf("threads=%d", threads), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newCompressWriter(&buf, compressGzip, 6, threads)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := gzip.NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("decompressed %d bytes, want the %d bytes written", len(got), len(data))
+			}
+		})
+	}
+}
+
+// BenchmarkCompressThreads compares single-threaded and multi-threaded
+// compression. The input defaults to 64 MiB; set OLLIE_BENCH_SIZE (e.g. 4GiB)
+// to measure a full-size model:
+//
+//	OLLIE_BENCH_SIZE=4GiB go test ./cmd -run '^$' -bench CompressThreads -benchtime 1x
+func BenchmarkCompressThreads(b *testing.B) {
+	size := int64(64 << 20)
+	if env := os.Getenv("OLLIE_BENCH_SIZE"); env != "" {
+		var err error
+		if size, err = parseSize(env); err != nil {
+			b.Fatal(err)
+		}
+	}
+	block := benchData(4 << 20)
+
+	for _, format := range []string{compressGzip, compressZstd} {
+		for _, threads := range []int{1, max(runtime.GOMAXPROCS(0), 4)} {
+			b.Run(fmt.Sprintf("%s/threads=%d", format, threads), func(b *testing.B) {
+				b.SetBytes(size)
+				for b.Loop() {
+					w, err := newCompressWriter(io.Discard, format, 0, threads)
+					if err != nil {
+						b.Fatal(err)
+					}
+					for written := int64(0); written < size; {
+						n, err := w.Write(block[:min(int64(len(block)), size-written)])
+						if err != nil {
+							b.Fatal(err)
+						}
+						written += int64(n)
+					}
+					if err := w.Close(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
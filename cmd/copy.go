@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// writeManifest writes manifest data for modelName into the models directory,
+// creating parent directories as needed. An existing manifest is only replaced
+// when force is set.
+func writeManifest(modelPath string, modelName *ModelName, data []byte, force bool) error {
+	manifestPath := filepath.Join(modelPath, manifestRelPath(modelName))
+	parentDir := filepath.Dir(manifestPath)
+
+	if err := os.MkdirAll(parentDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", parentDir, err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+
+	file, err := os.OpenFile(manifestPath, flags, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("model %s already exists (use --force to overwrite)", modelName.ShortString())
+		}
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	// Keep the new manifest owned by ollama:ollama, like extracted files
+	uid, gid, err := getOllamaUIDGID()
+	if err != nil {
+		slog.Warn("failed to get ollama UID/GID, proceeding without chown", "error", err)
+	}
+	if uid != -1 && gid != -1 {
+		for _, path := range []string{parentDir, manifestPath} {
+			if err := chown(path, uid, gid); err != nil {
+				slog.Warn("failed to set ownership", "path", path, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyModelManifest writes the manifest of source under dest, so dest shares
+// all of source's blobs, and returns the path of source's manifest. The source
+// must exist and its manifest must parse. An existing dest is only replaced
+// when force is set.
+func copyModelManifest(modelPath string, source, dest *ModelName, force bool) (string, error) {
+	if _, err := getFilePaths(source, modelPath, nil); err != nil {
+		return "", err
+	}
+
+	sourcePath := filepath.Join(modelPath, manifestRelPath(source))
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err := writeManifest(modelPath, dest, data, force); err != nil {
+		return "", err
+	}
+	return sourcePath, nil
+}
+
+var copyCmd = &cobra.Command{
+	Use:   "copy SOURCE DESTINATION",
+	Short: "Copy a model to a new name",
+	Long: `Copy an Ollama model by writing its manifest under a new name.
+Blobs are content-addressed and shared, so no blob data is duplicated.
+
+Examples:
+  ollie copy llama2:latest llama2:backup
+  ollie copy llama2 myorg/llama2:v1 --force`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Parse model names
+		source, err := parseModelName(args[0])
+		if err != nil {
+			return err
+		}
+		dest, err := parseModelName(args[1])
+		if err != nil {
+			return err
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if _, err := copyModelManifest(modelPath, source, dest, copyForce); err != nil {
+			return err
+		}
+
+		slog.Info("Copied model", "source", source.ShortString(), "dest", dest.ShortString())
+		return nil
+	},
+}
+
+var copyForce bool
+
+func init() {
+	copyCmd.Flags().BoolVarP(&copyForce, "force", "f", false, "overwrite the destination if it already exists")
+	rootCmd.AddCommand(copyCmd)
+}
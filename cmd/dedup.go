@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sharedBlob is a blob referenced by more than one model
+type sharedBlob struct {
+	Blob   string   `json:"blob"`
+	Size   int64    `json:"size"`
+	Models []string `json:"models"`
+}
+
+// duplicateBlobs is a set of blobs stored under different names with the same content
+type duplicateBlobs struct {
+	SHA256      string   `json:"sha256"`
+	Size        int64    `json:"size"`
+	Blobs       []string `json:"blobs"`
+	Reclaimable int64    `json:"reclaimable"`
+}
+
+// dedupReport summarizes how blob storage is shared between models
+type dedupReport struct {
+	Models      int              `json:"models"`
+	Blobs       int              `json:"blobs"`
+	LogicalSize int64            `json:"logical_size"`
+	StoreSize   int64            `json:"store_size"`
+	SavedSize   int64            `json:"saved_size"`
+	Shared      []sharedBlob     `json:"shared"`
+	Duplicates  []duplicateBlobs `json:"duplicates"`
+	Reclaimable int64            `json:"reclaimable"`
+}
+
+// buildDedupReport maps every blob to the models referencing it and sums the
+// size each model would use on its own against the size actually stored.
+// Blobs on disk of equal size are hashed to find identical content stored
+// under different names, which content addressing should never produce.
+func buildDedupReport(modelPath string) (*dedupReport, error) {
+	models, err := listModels(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dedupReport{Shared: []sharedBlob{}, Duplicates: []duplicateBlobs{}}
+	users := map[string][]string{}
+	sizes := map[string]int64{}
+	for _, model := range models {
+		layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(model)))
+		if err != nil {
+			slog.Warn("skipping unreadable model", "model", model.ShortString(), "error", err)
+			continue
+		}
+		report.Models++
+
+		seen := map[string]bool{}
+		for _, layer := range layers {
+			name := layer.BlobName()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			if _, ok := sizes[name]; !ok {
+				info, err := os.Stat(filepath.Join(modelPath, "blobs", name))
+				if err != nil {
+					slog.Warn("referenced blob is missing", "model", model.ShortString(), "blob", name)
+					continue
+				}
+				sizes[name] = info.Size()
+			}
+			users[name] = append(users[name], model.ShortString())
+			report.LogicalSize += sizes[name]
+		}
+	}
+
+	for name, size := range sizes {
+		report.Blobs++
+		report.StoreSize += size
+		if len(users[name]) > 1 {
+			report.Shared = append(report.Shared, sharedBlob{Blob: name, Size: size, Models: users[name]})
+		}
+	}
+	report.SavedSize = report.LogicalSize - report.StoreSize
+	slices.SortFunc(report.Shared, func(a, b sharedBlob) int {
+		return strings.Compare(a.Blob, b.Blob)
+	})
+
+	// Group every blob on disk by size, since only blobs of equal size can be duplicates
+	entries, err := os.ReadDir(filepath.Join(modelPath, "blobs"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read blobs directory: %w", err)
+	}
+	bySize := map[int64][]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !blobNamePattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat blob %s: %w", entry.Name(), err)
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], entry.Name())
+	}
+
+	for size, names := range bySize {
+		if len(names) < 2 || size == 0 {
+			continue
+		}
+		byHash := map[string][]string{}
+		for _, name := range names {
+			sum, err := fileSHA256(filepath.Join(modelPath, "blobs", name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash blob %s: %w", name, err)
+			}
+			byHash[sum] = append(byHash[sum], name)
+		}
+		for sum, same := range byHash {
+			if len(same) < 2 {
+				continue
+			}
+			slices.Sort(same)
+			reclaimable := size * int64(len(same)-1)
+			report.Duplicates = append(report.Duplicates, duplicateBlobs{SHA256: sum, Size: size, Blobs: same, Reclaimable: reclaimable})
+			report.Reclaimable += reclaimable
+		}
+	}
+	slices.SortFunc(report.Duplicates, func(a, b duplicateBlobs) int {
+		return strings.Compare(a.SHA256, b.SHA256)
+	})
+
+	return report, nil
+}
+
+var dedupReportCmd = &cobra.Command{
+	Use:   "dedup-report",
+	Short: "Report how much blob storage is shared between models",
+	Long: `Walk every manifest, map each blob to the models that reference it, and
+report how much disk the store uses compared to the models' combined size.
+
+The report shows:
+  - the logical size: what every model would take if stored on its own
+  - the store size: the blobs actually on disk, each counted once
+  - the blobs shared by more than one model
+  - duplicates: blobs with identical content stored under different names,
+    and the space that removing them would reclaim
+
+Blobs are content-addressed, so duplicates should never occur; any that are
+reported point at blobs whose names don't match their content (see "ollie verify").
+Use --json for machine-readable output.
+
+Examples:
+  ollie dedup-report
+  ollie dedup-report --json | jq .reclaimable`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		report, err := buildDedupReport(modelPath)
+		if err != nil {
+			return err
+		}
+
+		if dedupJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+
+		fmt.Printf("Models:        %d\n", report.Models)
+		fmt.Printf("Blobs:         %d\n", report.Blobs)
+		fmt.Printf("Logical size:  %s\n", formatBytes(report.LogicalSize))
+		fmt.Printf("Store size:    %s\n", formatBytes(report.StoreSize))
+		fmt.Printf("Saved:         %s by %d shared blob(s)\n", formatBytes(report.SavedSize), len(report.Shared))
+		fmt.Printf("Reclaimable:   %s in %d duplicate set(s)\n", formatBytes(report.Reclaimable), len(report.Duplicates))
+
+		if len(report.Shared) > 0 {
+			fmt.Println("\nShared blobs:")
+			for _, blob := range report.Shared {
+				fmt.Printf("  %s (%s): %s\n", blob.Blob, formatBytes(blob.Size), strings.Join(blob.Models, ", "))
+			}
+		}
+		if len(report.Duplicates) > 0 {
+			fmt.Println("\nDuplicate blobs:")
+			for _, dup := range report.Duplicates {
+				fmt.Printf("  sha256 %s (%s each): %s\n", dup.SHA256, formatBytes(dup.Size), strings.Join(dup.Blobs, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+var dedupJSON bool
+
+func init() {
+	dedupReportCmd.Flags().BoolVar(&dedupJSON, "json", false, "print the report as JSON")
+	rootCmd.AddCommand(dedupReportCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// referencedBlobs returns the blob file names referenced by every local manifest,
+// ignoring the manifests of the models in except. Models are matched by name
+// alone, so a digest or variant given with a name still excludes its manifest.
+func referencedBlobs(modelPath string, except ...*ModelName) (map[string]bool, error) {
+	models, err := listModels(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]bool{}
+	for _, model := range models {
+		if slices.ContainsFunc(except, func(m *ModelName) bool { return m.String() == model.String() }) {
+			continue
+		}
+
+		layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(model)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", model.ShortString(), err)
+		}
+		for _, layer := range layers {
+			refs[layer.BlobName()] = true
+		}
+	}
+
+	return refs, nil
+}
+
+// removeEmptyParents removes dir and its parents while they are empty, stopping at stop
+func removeEmptyParents(dir, stop string) {
+	for dir != stop && len(dir) > len(stop) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// resolveDeleteTarget returns the model a delete argument names. A pinned
+// @sha256 digest must match the tag's manifest, so a stale pin can't delete
+// whatever the tag points at now.
+func resolveDeleteTarget(name, modelPath string) (*ModelName, error) {
+	modelName, err := parseModelName(name)
+	if err != nil {
+		return nil, err
+	}
+	if modelName.Digest == "" {
+		return modelName, nil
+	}
+	resolved, err := resolveModelNames(name, modelPath, false)
+	if err != nil {
+		return nil, fmt.Errorf("refusing to delete %s: %w", name, err)
+	}
+	return resolved[0], nil
+}
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete MODEL_NAME",
+	Short: "Delete a model and its unreferenced blobs",
+	Long: `Delete an Ollama model by removing its manifest from the models directory.
+Blobs used by the model are removed too, unless another manifest still references them.
+A name pinned with @sha256:<digest> is only deleted if its manifest has that digest.
+
+Examples:
+  ollie delete llama2:backup
+  ollie delete llama2 --keep-blobs
+  ollie delete llama2:7b@sha256:<digest>
+  ollie delete llama2 --dry-run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		modelName, err := resolveDeleteTarget(args[0], modelPath)
+		if err != nil {
+			return err
+		}
+
+		manifestPath := filepath.Join(modelPath, manifestRelPath(modelName))
+		layers, err := parseManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+
+		// Find the model's blobs that no other manifest references
+		orphans := []string{}
+		if !deleteKeepBlobs {
+			refs, err := referencedBlobs(modelPath, modelName)
+			if err != nil {
+				return err
+			}
+			seen := map[string]bool{}
+			for _, layer := range layers {
+				name := layer.BlobName()
+				if !refs[name] && !seen[name] {
+					seen[name] = true
+					orphans = append(orphans, name)
+				}
+			}
+		}
+
+		if deleteDryRun {
+			fmt.Fprintf(os.Stderr, "Would delete manifest %s\n", manifestRelPath(modelName))
+			var total int64
+			for _, name := range orphans {
+				if info, err := os.Stat(filepath.Join(modelPath, "blobs", name)); err == nil {
+					total += info.Size()
+				}
+				fmt.Fprintf(os.Stderr, "Would delete blob %s\n", name)
+			}
+			fmt.Fprintf(os.Stderr, "Would free %s (%d blob(s))\n", formatBytes(total), len(orphans))
+			return nil
+		}
+
+		if err := os.Remove(manifestPath); err != nil {
+			return fmt.Errorf("failed to delete manifest: %w", err)
+		}
+		removeEmptyParents(filepath.Dir(manifestPath), filepath.Join(modelPath, "manifests"))
+
+		for _, name := range orphans {
+			if err := os.Remove(filepath.Join(modelPath, "blobs", name)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete blob %s: %w", name, err)
+			}
+		}
+
+		slog.Info("Deleted model", "model", modelName.ShortString(), "blobs", len(orphans))
+		return nil
+	},
+}
+
+var (
+	deleteKeepBlobs bool
+	deleteDryRun    bool
+)
+
+func init() {
+	deleteCmd.Flags().BoolVar(&deleteKeepBlobs, "keep-blobs", false, "only delete the manifest, leaving all blobs in place")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "report what would be deleted without removing anything")
+	rootCmd.AddCommand(deleteCmd)
+}
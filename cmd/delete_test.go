@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// manifestDigest returns the sha256 of a model's manifest in modelPath
+func manifestDigest(t *testing.T, modelPath, name string) string {
+	t.Helper()
+	modelName, err := parseModelName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := fileSHA256(filepath.Join(modelPath, manifestRelPath(modelName)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
+
+func TestReferencedBlobsExcept(t *testing.T) {
+	modelPath := newTestStore(t)
+	own := addTestModel(t, modelPath, "llama2:7b", testLayer{modelMediaType, "llama2 weights"})
+	other := addTestModel(t, modelPath, "mistral:latest", testLayer{modelMediaType, "mistral weights"})
+	digest := manifestDigest(t, modelPath, "llama2:7b")
+
+	tests := []struct {
+		name   string
+		except *ModelName
+	}{
+		{"plain name", &ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "llama2", Tag: "7b"}},
+		{"pinned digest", &ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "llama2", Tag: "7b", Digest: "sha256:" + digest}},
+		{"variant", &ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "llama2", Tag: "7b", Variant: "q4_0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := referencedBlobs(modelPath, tt.except)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refs[own.Layers[0].BlobName()] {
+				t.Errorf("blob of the excluded model %s is still referenced", tt.except.ShortString())
+			}
+			if !refs[other.Layers[0].BlobName()] {
+				t.Errorf("blob of mistral:latest isn't referenced")
+			}
+			// The config blob is identical for both models, so it stays referenced
+			if !refs[own.Config.BlobName()] {
+				t.Errorf("shared config blob isn't referenced")
+			}
+		})
+	}
+}
+
+func TestResolveDeleteTarget(t *testing.T) {
+	modelPath := newTestStore(t)
+	addTestModel(t, modelPath, "llama2:7b", testLayer{modelMediaType, "llama2 weights"})
+	addTestModel(t, modelPath, "llama2:13b", testLayer{modelMediaType, "llama2 13b weights"})
+	digest := manifestDigest(t, modelPath, "llama2:7b")
+	stale := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{"llama2:7b", "llama2:7b", ""},
+		{"llama2:7b@sha256:" + digest, "llama2:7b", ""},
+		{"llama2@sha256:" + digest, "llama2:7b", ""},
+		{"llama2:7b@sha256:" + stale, "", "refusing to delete"},
+		{"llama2:13b@sha256:" + digest, "", "refusing to delete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDeleteTarget(tt.name, modelPath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("resolveDeleteTarget(%q) = %v, %v; want an error containing %q", tt.name, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ShortString() != tt.want || got.Digest != "" {
+				t.Errorf("resolveDeleteTarget(%q) = %+v, want %s", tt.name, *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// modelDiff lists the blobs two models share and those only one of them has
+type modelDiff struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Shared []Layer `json:"shared"`
+	OnlyA  []Layer `json:"only_a"`
+	OnlyB  []Layer `json:"only_b"`
+}
+
+// diffLayers compares two models' blobs by digest, keeping manifest order
+func diffLayers(a, b []Layer) (shared, onlyA, onlyB []Layer) {
+	inA := map[string]bool{}
+	for _, layer := range a {
+		inA[layer.Digest] = true
+	}
+	inB := map[string]bool{}
+	for _, layer := range b {
+		inB[layer.Digest] = true
+	}
+
+	shared, onlyA, onlyB = []Layer{}, []Layer{}, []Layer{}
+	for _, layer := range a {
+		if inB[layer.Digest] {
+			shared = append(shared, layer)
+		} else {
+			onlyA = append(onlyA, layer)
+		}
+	}
+	for _, layer := range b {
+		if !inA[layer.Digest] {
+			onlyB = append(onlyB, layer)
+		}
+	}
+	return shared, onlyA, onlyB
+}
+
+// layersSize sums the sizes recorded for layers
+func layersSize(layers []Layer) int64 {
+	var total int64
+	for _, layer := range layers {
+		total += layer.Size
+	}
+	return total
+}
+
+// resolveModelLayers resolves a model name to a single local model and parses its manifest
+func resolveModelLayers(name, modelPath string) (*ModelName, []Layer, error) {
+	resolved, err := resolveModelNames(name, modelPath, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(resolved[0])))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved[0], layers, nil
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff MODEL_A MODEL_B",
+	Short: "Compare the blobs of two models",
+	Long: `Compare two models' manifests and list which blobs they share and which
+only one of them has, along with each blob's media type and size. This shows,
+for example, how a fine-tuned tag differs from its base model.
+
+Blobs are listed in a unified format: shared blobs are prefixed with a space,
+blobs only in MODEL_A with "-", and blobs only in MODEL_B with "+".
+Use --json for machine-readable output.
+
+Examples:
+  ollie diff llama2:7b llama2:7b-chat
+  ollie diff mymodel:base mymodel:tuned --json`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeModelNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		modelA, layersA, err := resolveModelLayers(args[0], modelPath)
+		if err != nil {
+			return err
+		}
+		modelB, layersB, err := resolveModelLayers(args[1], modelPath)
+		if err != nil {
+			return err
+		}
+
+		diff := modelDiff{A: modelA.ShortString(), B: modelB.ShortString()}
+		diff.Shared, diff.OnlyA, diff.OnlyB = diffLayers(layersA, layersB)
+
+		if diffJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(diff)
+		}
+
+		fmt.Printf("--- %s\n+++ %s\n", diff.A, diff.B)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, group := range []struct {
+			prefix string
+			layers []Layer
+		}{{" ", diff.Shared}, {"-", diff.OnlyA}, {"+", diff.OnlyB}} {
+			for _, layer := range group.layers {
+				fmt.Fprintf(w, "%s %s\t%s\t%s\n", group.prefix, layer.Digest, layer.MediaType, formatBytes(layer.Size))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d shared (%s), %d only in %s (%s), %d only in %s (%s)\n",
+			len(diff.Shared), formatBytes(layersSize(diff.Shared)),
+			len(diff.OnlyA), diff.A, formatBytes(layersSize(diff.OnlyA)),
+			len(diff.OnlyB), diff.B, formatBytes(layersSize(diff.OnlyB)))
+		return nil
+	},
+}
+
+var diffJSON bool
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print the comparison as JSON")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// Check results reported by doctor
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// doctorReport prints check results and counts failures
+type doctorReport struct {
+	failed int
+}
+
+func (r *doctorReport) add(result, format string, args ...any) {
+	if result == checkFail {
+		r.failed++
+	}
+	fmt.Printf("%s  %s\n", result, fmt.Sprintf(format, args...))
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common problems with the local model store",
+	Long: `Check the Ollama models directory for common problems and print a
+PASS/WARN/FAIL line for each check:
+
+  - the models directory (configured or the default) exists
+  - the ollama user and group exist
+  - every manifest parses and its blobs are present
+  - no blobs are orphaned (use "ollie gc" to remove them)
+  - blobs are owned by the ollama user and group and are readable
+
+Blob contents aren't hashed; use "ollie verify --all" for that.
+The command exits with a non-zero status if any check fails.
+
+Examples:
+  ollie doctor`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Failed checks aren't usage errors
+		cmd.SilenceUsage = true
+
+		report := &doctorReport{}
+
+		// Models directory
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			report.add(checkFail, "models directory: %v", err)
+			return fmt.Errorf("%d check(s) failed", report.failed)
+		}
+		if _, source := configuredModelsPath(); source != "" {
+			report.add(checkPass, "models directory %s (from %s)", modelPath, source)
+		} else {
+			report.add(checkPass, "models directory %s", modelPath)
+		}
+
+		// Service user
+		uid, gid, err := getOllamaUIDGID()
+		switch {
+		case err != nil:
+			report.add(checkWarn, "ollama user: %v", err)
+		case uid == -1 || gid == -1:
+			report.add(checkWarn, "ollama user or group not found; files keep their current owner")
+		default:
+			report.add(checkPass, "ollama user and group exist (%d:%d)", uid, gid)
+		}
+
+		// Manifests and the blobs they reference
+		models, err := listModels(modelPath)
+		if err != nil {
+			report.add(checkFail, "manifests: %v", err)
+			return fmt.Errorf("%d check(s) failed", report.failed)
+		}
+		refs := map[string]bool{}
+		corrupt, incomplete := 0, 0
+		for _, model := range models {
+			layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(model)))
+			if err != nil {
+				report.add(checkFail, "%s: %v", model.ShortString(), err)
+				corrupt++
+				continue
+			}
+			missing := 0
+			for _, layer := range layers {
+				refs[layer.BlobName()] = true
+				if _, err := os.Stat(filepath.Join(modelPath, "blobs", layer.BlobName())); err != nil {
+					missing++
+				}
+			}
+			if missing > 0 {
+				report.add(checkFail, "%s references %d missing blob(s)", model.ShortString(), missing)
+				incomplete++
+			}
+		}
+		if corrupt == 0 && incomplete == 0 {
+			report.add(checkPass, "%d model(s), all manifests valid and blobs present", len(models))
+		}
+
+		// Orphaned blobs, which can't be judged while some manifests are unreadable
+		if corrupt > 0 {
+			report.add(checkWarn, "orphaned blobs: skipped because %d manifest(s) couldn't be read", corrupt)
+		} else if orphans, err := orphanedBlobs(modelPath, refs); err != nil {
+			report.add(checkFail, "orphaned blobs: %v", err)
+		} else if len(orphans) > 0 {
+			var total int64
+			for _, blob := range orphans {
+				total += blob.Size()
+			}
+			report.add(checkWarn, "%d orphaned blob(s) using %s (run \"ollie gc\" to remove them)", len(orphans), formatBytes(total))
+		} else {
+			report.add(checkPass, "no orphaned blobs")
+		}
+
+		// Blob ownership and permissions
+		entries, err := os.ReadDir(filepath.Join(modelPath, "blobs"))
+		if err != nil && !os.IsNotExist(err) {
+			report.add(checkFail, "blobs directory: %v", err)
+		}
+		misowned, unreadable := 0, 0
+		for _, entry := range entries {
+			if entry.IsDir() || !blobNamePattern.MatchString(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if info.Mode().Perm()&0o044 != 0o044 {
+				unreadable++
+			}
+			if owner, group, ok := fileOwner(info); ok && uid != -1 && gid != -1 && (owner != uid || group != gid) {
+				misowned++
+			}
+		}
+		if misowned > 0 {
+			report.add(checkWarn, "%d blob(s) not owned by the ollama user and group", misowned)
+		}
+		if unreadable > 0 {
+			report.add(checkWarn, "%d blob(s) not readable by group and others", unreadable)
+		}
+		if misowned == 0 && unreadable == 0 {
+			report.add(checkPass, "blob ownership and permissions are consistent")
+		}
+
+		if report.failed > 0 {
+			return fmt.Errorf("%d check(s) failed", report.failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
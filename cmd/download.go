@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errDownloadInterrupted marks a download that failed partway through and can
+// be retried from where it stopped
+var errDownloadInterrupted = errors.New("download interrupted")
+
+// retryable reports whether a failed GET is worth retrying: connection errors,
+// server errors, and rate limiting
+func retryable(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryDelay returns the backoff before retry attempt n (starting at 1),
+// doubling --retry-delay each time
+func retryDelay(attempt int) time.Duration {
+	return loadRetryDelay << (attempt - 1)
+}
+
+// getWithRetry sends a GET request, retrying connection errors and 5xx
+// responses up to --retries times with exponential backoff. The last response
+// or error is returned once the attempts run out. Canceling ctx aborts the
+// request and any read of the response body.
+func getWithRetry(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", url, err)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		// Redirects are followed by the default client
+		resp, err := http.DefaultClient.Do(req)
+		if ctx.Err() != nil {
+			if err == nil {
+				resp.Body.Close()
+			}
+			return nil, contextError(ctx)
+		}
+		if !retryable(resp, err) || attempt >= loadRetries {
+			if err != nil {
+				return nil, fmt.Errorf("failed to download %s: %w", url, err)
+			}
+			return resp, nil
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			resp.Body.Close()
+		}
+		delay := retryDelay(attempt + 1)
+		slog.Warn("download failed, retrying", "attempt", attempt+1, "retries", loadRetries, "delay", delay, "error", reason)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// downloadDir returns the directory resumable downloads are kept in, creating
+// it if needed. It's private to the current user so other users can't plant
+// or read files there.
+func downloadDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find download directory: %w", err)
+	}
+	dir := filepath.Join(cacheDir, "ollie", "downloads")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	// MkdirAll leaves the mode of an existing directory alone
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	return dir, nil
+}
+
+// downloadPaths returns where a resumable download of url is kept in dir while
+// it's in progress and once it's complete. The names are derived from the URL
+// so a later run can find them, and keep its file name so the extension still
+// hints at the compression format.
+func downloadPaths(dir, url string) (partial, complete string) {
+	sum := sha256.Sum256([]byte(url))
+	base := path.Base(sourceFileName(url))
+	if base == "." || base == "/" {
+		base = "download"
+	}
+	complete = filepath.Join(dir, "ollie-"+hex.EncodeToString(sum[:8])+"-"+base)
+	return complete + ".partial", complete
+}
+
+// openPartialDownload opens a partial download for appending, creating it if
+// there is none yet. Symlinks aren't followed, and a new file is created
+// exclusively.
+func openPartialDownload(partial string) (*os.File, error) {
+	file, err := os.OpenFile(partial, os.O_WRONLY|oNoFollow, 0)
+	if !os.IsNotExist(err) {
+		return file, err
+	}
+	return os.OpenFile(partial, os.O_CREATE|os.O_EXCL|os.O_WRONLY|oNoFollow, 0o600)
+}
+
+// reusableDownload reports whether a finished download can be used as is:
+// it must be a regular file matching expectedSum. Without a sum to check
+// against, it's downloaded again.
+func reusableDownload(complete, expectedSum string) bool {
+	info, err := os.Lstat(complete)
+	if err != nil || !info.Mode().IsRegular() || expectedSum == "" {
+		return false
+	}
+	actual, err := fileSHA256(complete)
+	return err == nil && actual == expectedSum
+}
+
+// downloadResumable downloads url to a file in the download directory and
+// returns its path. A partial file left by an earlier attempt is continued
+// with a Range request, and a finished download is reused once it matches
+// expectedSum. Servers that don't honor the range get the download restarted
+// from the beginning.
+func downloadResumable(ctx context.Context, url, expectedSum string, quiet bool) (string, error) {
+	dir, err := downloadDir()
+	if err != nil {
+		return "", err
+	}
+	partial, complete := downloadPaths(dir, url)
+	if reusableDownload(complete, expectedSum) {
+		slog.Info("Using previously downloaded tarball", "path", complete)
+		return complete, nil
+	}
+	if err := os.Remove(complete); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove previous download: %w", err)
+	}
+
+	file, err := openPartialDownload(partial)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", fmt.Errorf("failed to read download file: %w", err)
+	}
+
+	header := http.Header{}
+	if offset > 0 {
+		header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	resp, err := getWithRetry(ctx, url, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && contentRangeStart(resp.Header.Get("Content-Range")) == offset:
+		slog.Info("Resuming download", "offset", offset)
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range (or there was nothing to resume)
+		if offset > 0 {
+			slog.Warn("server doesn't support resuming, restarting download")
+		}
+		if err := file.Truncate(0); err != nil {
+			return "", fmt.Errorf("failed to truncate download file: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to rewind download file: %w", err)
+		}
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable || resp.StatusCode == http.StatusPartialContent:
+		// The partial file doesn't fit what the server has, so start over
+		slog.Warn("partial download doesn't match the server's copy, restarting download")
+		file.Close()
+		if err := os.Remove(partial); err != nil {
+			return "", fmt.Errorf("failed to remove partial download: %w", err)
+		}
+		return downloadResumable(ctx, url, expectedSum, quiet)
+	default:
+		return "", fmt.Errorf("failed to download %s: server returned %s", url, resp.Status)
+	}
+
+	var total int64
+	if resp.ContentLength > 0 {
+		total = offset + resp.ContentLength
+	}
+	bar := newProgressBar("Downloading", total, quiet)
+	bar.Add(offset)
+	if _, err := io.Copy(file, io.TeeReader(resp.Body, bar)); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%w (rerun with --resume to continue)", contextError(ctx))
+		}
+		return "", fmt.Errorf("%w (rerun with --resume to continue): %w", errDownloadInterrupted, err)
+	}
+	bar.Finish()
+
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write download file: %w", err)
+	}
+	if err := os.Rename(partial, complete); err != nil {
+		return "", fmt.Errorf("failed to finish download: %w", err)
+	}
+	return complete, nil
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header
+// such as "bytes 100-199/200", or -1 if it can't be parsed
+func contentRangeStart(header string) int64 {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
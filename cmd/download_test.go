@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+// setTestCacheDir points os.UserCacheDir under a fresh directory for the rest
+// of the test
+func setTestCacheDir(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	setTestHome(t, home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv("LocalAppData", filepath.Join(home, "cache"))
+}
+
+func TestDownloadResumableReuse(t *testing.T) {
+	setTestCacheDir(t)
+	const body = "tarball contents"
+	sum := sha256.Sum256([]byte(body))
+	expected := hex.EncodeToString(sum[:])
+
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+	url := ts.URL + "/llama2.tar"
+
+	path, err := downloadResumable(context.Background(), url, expected, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != 0o700 {
+			t.Errorf("download directory has mode %#o, want 0700", got)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+		wantGet  bool
+	}{
+		{"matching checksum", body, expected, false},
+		{"no checksum", body, "", true},
+		{"mismatched checksum", "tampered", expected, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			before := requests.Load()
+			got, err := downloadResumable(context.Background(), url, tt.expected, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched := requests.Load() != before; fetched != tt.wantGet {
+				t.Errorf("downloaded again = %v, want %v", fetched, tt.wantGet)
+			}
+			data, err := os.ReadFile(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != body {
+				t.Errorf("download = %q, want %q", data, body)
+			}
+		})
+	}
+}
+
+func TestDownloadResumableSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	setTestCacheDir(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tarball contents"))
+	}))
+	defer ts.Close()
+	url := ts.URL + "/llama2.tar"
+
+	// A partial download planted as a symlink isn't written through
+	dir, err := downloadDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "target")
+	if err := os.WriteFile(target, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	partial, _ := downloadPaths(dir, url)
+	if err := os.Symlink(target, partial); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadResumable(context.Background(), url, "", true); err == nil {
+		t.Error("downloadResumable followed a symlinked partial download")
+	}
+	if info, err := os.Stat(target); err != nil || info.Size() != 0 {
+		t.Errorf("symlink target was written to: %v, %v", info, err)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"ollie/pkg/ollama"
+)
+
+// Sentinel errors that scripts can tell apart by exit code
+var (
+	// ErrModelNotFound indicates the requested model has no manifest locally
+	ErrModelNotFound = ollama.ErrModelNotFound
+	// ErrManifestCorrupt indicates a manifest exists but couldn't be parsed
+	ErrManifestCorrupt = ollama.ErrManifestCorrupt
+)
+
+// Process exit codes returned by Execute
+const (
+	exitError           = 1
+	exitModelNotFound   = 2
+	exitManifestCorrupt = 3
+	// exitInterrupted follows the shell convention for a process ended by SIGINT
+	exitInterrupted = 130
+)
+
+// exitCode maps an error returned by a command to the process exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrModelNotFound):
+		return exitModelNotFound
+	case errors.Is(err, ErrManifestCorrupt):
+		return exitManifestCorrupt
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	default:
+		return exitError
+	}
+}
+
+// reportError prints err to stderr, as JSON when jsonErrors is set, and returns its exit code
+func reportError(err error) int {
+	code := exitCode(err)
+	if jsonErrors {
+		data, _ := json.Marshal(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{err.Error(), code})
+		fmt.Fprintln(os.Stderr, string(data))
+		return code
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return code
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"ollie/pkg/ollama"
+
+	"github.com/spf13/cobra"
+)
+
+// ociLayoutVersion is the image layout version written to the oci-layout file
+const ociLayoutVersion = "1.0.0"
+
+// ociIndex is the index.json at the root of an OCI image layout, listing the
+// manifests it holds
+type ociIndex struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	MediaType     string              `json:"mediaType"`
+	Manifests     []ollama.IndexEntry `json:"manifests"`
+}
+
+// readOCIIndex reads the index.json of an existing layout in dir, or returns an
+// empty index when there is none yet
+func readOCIIndex(dir string) (*ociIndex, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &ociIndex{SchemaVersion: manifestSchemaVersion, MediaType: ollama.IndexMediaType, Manifests: []ollama.IndexEntry{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index.json: %w", err)
+	}
+	var index ociIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, "index.json"), err)
+	}
+	return &index, nil
+}
+
+// ociBlobPath returns where the blob with digest is stored in an OCI layout
+func ociBlobPath(dir, digest string) string {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	return filepath.Join(dir, "blobs", algorithm, encoded)
+}
+
+// exportBlob places the blob at src into an OCI layout at dest, hard linking it
+// when both are on the same filesystem and copying it otherwise. A blob already
+// in the layout with the right size is left alone.
+func exportBlob(src, dest string, size int64, bar *progressBar) error {
+	if info, err := os.Stat(dest); err == nil && info.Size() == size {
+		bar.Add(size)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dest), err)
+	}
+	if err := os.Link(src, dest); err == nil {
+		bar.Add(size)
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open blob: %w", err)
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	if _, err := io.Copy(out, io.TeeReader(in, bar)); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("failed to copy blob to %s: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("failed to copy blob to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// exportOCI writes a model into an OCI image layout in dir: its manifest and
+// every blob it references go under blobs/sha256/, and index.json lists the
+// manifest under the model's tag, replacing an entry with the same tag.
+// Exporting more models into the same dir adds them to the layout.
+func exportOCI(modelPath string, modelName *ModelName, dir string) error {
+	manifestPath := filepath.Join(modelPath, manifestRelPath(modelName))
+	layers, err := parseManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	manifest, err := readManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := checkFilesExist(modelPath, layerBlobPaths(layers)); err != nil {
+		return err
+	}
+
+	index, err := readOCIIndex(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	layout, _ := json.Marshal(map[string]string{"imageLayoutVersion": ociLayoutVersion})
+	if err := os.WriteFile(filepath.Join(dir, "oci-layout"), layout, 0o644); err != nil {
+		return fmt.Errorf("failed to write oci-layout: %w", err)
+	}
+
+	bar := newProgressBar("Exporting", layersSize(layers), quiet || verbose)
+	defer bar.Finish()
+	for _, layer := range layers {
+		if verbose {
+			slog.Info("Exporting blob", "digest", layer.Digest, "size", formatBytes(layer.Size))
+		}
+		if err := exportBlob(filepath.Join(modelPath, "blobs", layer.BlobName()), ociBlobPath(dir, layer.Digest), layer.Size, bar); err != nil {
+			return err
+		}
+		bar.File(layer.Digest)
+	}
+	bar.Finish()
+
+	// The manifest itself is a blob, addressed by the digest of its bytes
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if err := os.WriteFile(ociBlobPath(dir, digest), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest blob: %w", err)
+	}
+
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType = manifestMediaType
+	}
+	index.Manifests = slices.DeleteFunc(index.Manifests, func(entry ollama.IndexEntry) bool {
+		return entry.Annotations[ollama.RefNameAnnotation] == modelName.Tag
+	})
+	index.Manifests = append(index.Manifests, ollama.IndexEntry{
+		MediaType:   mediaType,
+		Digest:      digest,
+		Size:        int64(len(data)),
+		Annotations: map[string]string{ollama.RefNameAnnotation: modelName.Tag},
+	})
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), append(indexData, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write index.json: %w", err)
+	}
+	return nil
+}
+
+// layerBlobPaths returns the blobs directory paths of layers, relative to the models directory
+func layerBlobPaths(layers []Layer) []string {
+	paths := []string{}
+	for _, layer := range layers {
+		paths = append(paths, filepath.Join("blobs", layer.BlobName()))
+	}
+	return paths
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export --oci MODEL_NAME DIR",
+	Short: "Export a model in another layout, such as an OCI image layout",
+	Long: `Export a model from the models directory into a format other tools can
+consume, without going through a tarball.
+
+With --oci, the model is written to DIR as an OCI image layout: an oci-layout
+file, an index.json listing the manifest under the model's tag, and the
+manifest, config, and layers under blobs/sha256/. The layout can then be pushed
+to a container registry with tools such as oras or skopeo. Blobs are hard
+linked from the models directory when DIR is on the same filesystem, and copied
+otherwise. Exporting another model into the same DIR adds it to the layout,
+replacing any manifest already listed under the same tag.
+
+Examples:
+  ollie export --oci llama2:7b ./llama2-oci
+  oras cp --from-oci-layout ./llama2-oci:7b registry.example.com/llama2:7b
+  skopeo copy oci:./llama2-oci:7b docker://registry.example.com/llama2:7b`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !exportOCIFlag {
+			return fmt.Errorf("specify an export format (supported: --oci)")
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		resolved, err := resolveModelNames(args[0], modelPath, false)
+		if err != nil {
+			return err
+		}
+		modelName := resolved[0]
+
+		if err := exportOCI(modelPath, modelName, args[1]); err != nil {
+			return err
+		}
+		slog.Info("Exported model", "model", modelName.ShortString(), "layout", args[1], "ref", modelName.Tag)
+		return nil
+	},
+}
+
+var exportOCIFlag bool
+
+func init() {
+	exportCmd.Flags().BoolVar(&exportOCIFlag, "oci", false, "write an OCI image layout directory")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// orphanedBlobs returns the blobs in the models directory that refs doesn't contain.
+// Only complete blobs are considered, so in-progress downloads are never returned.
+func orphanedBlobs(modelPath string, refs map[string]bool) ([]fs.FileInfo, error) {
+	blobsDir := filepath.Join(modelPath, "blobs")
+	entries, err := os.ReadDir(blobsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read blobs directory: %w", err)
+	}
+
+	orphans := []fs.FileInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !blobNamePattern.MatchString(name) || refs[name] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat blob %s: %w", name, err)
+		}
+		orphans = append(orphans, info)
+	}
+
+	return orphans, nil
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove blobs that no model references",
+	Long: `Remove blobs from the models directory that are not referenced by any manifest.
+Interrupted pulls and manual deletes can leave such orphaned blobs behind.
+In-progress downloads (blobs with a -partial suffix) are left alone.
+Models matched by .ollieignore still count as references, so their blobs are
+never collected.
+
+Examples:
+  ollie gc --dry-run
+  ollie gc`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		refs, err := referencedBlobs(modelPath)
+		if err != nil {
+			return err
+		}
+
+		orphans, err := orphanedBlobs(modelPath, refs)
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, blob := range orphans {
+			name := blob.Name()
+			if gcDryRun {
+				fmt.Fprintf(os.Stderr, "Would delete blob %s (%s)\n", name, formatBytes(blob.Size()))
+			} else if err := os.Remove(filepath.Join(modelPath, "blobs", name)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete blob %s: %w", name, err)
+			}
+			total += blob.Size()
+		}
+
+		if gcDryRun {
+			fmt.Fprintf(os.Stderr, "Would free %s (%d blob(s))\n", formatBytes(total), len(orphans))
+			return nil
+		}
+		slog.Info("Freed space", "size", formatBytes(total), "blobs", len(orphans))
+		return nil
+	},
+}
+
+var gcDryRun bool
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "report what would be deleted without removing anything")
+	rootCmd.AddCommand(gcCmd)
+}
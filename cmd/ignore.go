@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is read from the models directory and the current directory
+const ignoreFileName = ".ollieignore"
+
+// ignoreRule is one pattern from an ignore file
+type ignoreRule struct {
+	pattern string
+	negate  bool
+}
+
+// ignoreMatcher decides which models bulk commands skip. Rules are applied in
+// order and the last matching rule wins, as in .gitignore.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// loadIgnoreMatcher reads .ollieignore from the models directory and then from
+// the current directory, so rules in the current directory take precedence.
+// Missing files are ignored.
+func loadIgnoreMatcher(modelPath string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	paths := []string{filepath.Join(modelPath, ignoreFileName)}
+	if cwd, err := os.Getwd(); err == nil && filepath.Clean(cwd) != filepath.Clean(modelPath) {
+		paths = append(paths, filepath.Join(cwd, ignoreFileName))
+	}
+
+	for _, p := range paths {
+		if err := m.readFile(p); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// readFile appends the rules in the ignore file at p
+func (m *ignoreMatcher) readFile(p string) error {
+	file, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{pattern: line}
+		if strings.HasPrefix(line, "!") {
+			rule = ignoreRule{pattern: line[1:], negate: true}
+		}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q in %s: %w", line, p, err)
+		}
+		m.rules = append(m.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return nil
+}
+
+// matches reports whether pattern matches the model's short or fully qualified
+// name. A pattern without a tag matches every tag of the model.
+func (r ignoreRule) matches(modelName *ModelName) bool {
+	names := []string{modelName.ShortString(), modelName.String()}
+	if !strings.Contains(r.pattern, ":") {
+		short := modelName.ShortString()
+		full := modelName.String()
+		names = append(names, short[:strings.LastIndex(short, ":")], full[:strings.LastIndex(full, ":")])
+	}
+	for _, name := range names {
+		if ok, _ := path.Match(r.pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignored reports whether the model should be skipped
+func (m *ignoreMatcher) Ignored(modelName *ModelName) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(modelName) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// listBulkModels returns the local models that bulk commands operate on,
+// leaving out those matched by .ollieignore
+func listBulkModels(modelPath string) ([]*ModelName, error) {
+	models, err := listModels(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := loadIgnoreMatcher(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []*ModelName{}
+	for _, model := range models {
+		if !matcher.Ignored(model) {
+			kept = append(kept, model)
+		}
+	}
+	return kept, nil
+}
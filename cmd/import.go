@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// modelfile holds the directives ollie understands from an Ollama Modelfile
+type modelfile struct {
+	From       string
+	Template   string
+	System     string
+	License    string
+	Parameters map[string]any
+}
+
+// parseModelfile reads FROM, TEMPLATE, SYSTEM, LICENSE, and PARAMETER directives.
+// Values may be quoted or wrapped in triple quotes to span multiple lines.
+func parseModelfile(r io.Reader) (*modelfile, error) {
+	mf := &modelfile{Parameters: map[string]any{}}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		directive, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		// Collect a """multi-line""" value
+		if strings.HasPrefix(value, `"""`) {
+			value = strings.TrimPrefix(value, `"""`)
+			for !strings.HasSuffix(value, `"""`) {
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("unterminated \"\"\" in %s", directive)
+				}
+				value += "\n" + scanner.Text()
+			}
+			value = strings.TrimSuffix(value, `"""`)
+		} else if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+
+		switch strings.ToUpper(directive) {
+		case "FROM":
+			mf.From = value
+		case "TEMPLATE":
+			mf.Template = value
+		case "SYSTEM":
+			mf.System = value
+		case "LICENSE":
+			mf.License = value
+		case "PARAMETER":
+			key, raw, ok := strings.Cut(value, " ")
+			if !ok {
+				return nil, fmt.Errorf("invalid PARAMETER: %s", value)
+			}
+			addParameter(mf.Parameters, key, strings.TrimSpace(raw))
+		default:
+			return nil, fmt.Errorf("unsupported Modelfile directive: %s", directive)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Modelfile: %w", err)
+	}
+
+	return mf, nil
+}
+
+// addParameter stores a PARAMETER value, converting numbers and booleans and
+// collecting repeated stop sequences into a list
+func addParameter(params map[string]any, key, raw string) {
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	if key == "stop" {
+		stops, _ := params[key].([]string)
+		params[key] = append(stops, raw)
+		return
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		params[key] = n
+	} else if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		params[key] = f
+	} else if b, err := strconv.ParseBool(raw); err == nil {
+		params[key] = b
+	} else {
+		params[key] = raw
+	}
+}
+
+// writeBlob streams r into the blobs directory under its sha256 digest and
+// returns a layer describing it. Existing blobs with the same digest are kept.
+func writeBlob(modelPath, mediaType string, r io.Reader) (Layer, error) {
+	blobsDir := filepath.Join(modelPath, "blobs")
+	if err := os.MkdirAll(blobsDir, os.ModePerm); err != nil {
+		return Layer{}, fmt.Errorf("failed to create directory %s: %w", blobsDir, err)
+	}
+
+	tmp, err := os.CreateTemp(blobsDir, "import-*.partial")
+	if err != nil {
+		return Layer{}, fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(r, hasher))
+	if err != nil {
+		tmp.Close()
+		return Layer{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Layer{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	layer := Layer{
+		MediaType: mediaType,
+		Digest:    "sha256:" + hex.EncodeToString(hasher.Sum(nil)),
+		Size:      size,
+	}
+	blobPath := filepath.Join(blobsDir, layer.BlobName())
+
+	if _, err := os.Stat(blobPath); err == nil {
+		return layer, nil
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return Layer{}, fmt.Errorf("failed to set blob permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		return Layer{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	// Keep the new blob owned by ollama:ollama, like extracted files
+	uid, gid, err := getOllamaUIDGID()
+	if err != nil {
+		slog.Warn("failed to get ollama UID/GID, proceeding without chown", "error", err)
+	}
+	if uid != -1 && gid != -1 {
+		if err := chown(blobPath, uid, gid); err != nil {
+			slog.Warn("failed to set ownership for blob", "blob", blobPath, "error", err)
+		}
+	}
+
+	return layer, nil
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import --gguf FILE --name MODEL_NAME",
+	Short: "Register a GGUF file as a local model",
+	Long: `Import a GGUF model file into the Ollama models directory without running
+the Ollama server. The file is copied into the blob store, and a config blob
+and manifest are created for the given model name.
+
+A Modelfile can be given with --modelfile to add a TEMPLATE, SYSTEM prompt,
+LICENSE, and PARAMETER values. Its FROM line is used as the GGUF file when
+--gguf is not set, resolved relative to the Modelfile.
+
+Examples:
+  ollie import --gguf mistral-7b.Q4_K_M.gguf --name mistral:7b
+  ollie import --modelfile Modelfile --name mymodel:latest`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modelName, err := parseModelName(importName)
+		if err != nil {
+			return err
+		}
+
+		// Read the Modelfile, if any
+		mf := &modelfile{}
+		if importModelfile != "" {
+			file, err := os.Open(importModelfile)
+			if err != nil {
+				return fmt.Errorf("failed to open Modelfile: %w", err)
+			}
+			mf, err = parseModelfile(file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		ggufPath := importGGUF
+		if ggufPath == "" && mf.From != "" {
+			ggufPath = mf.From
+			if !filepath.IsAbs(ggufPath) {
+				ggufPath = filepath.Join(filepath.Dir(importModelfile), ggufPath)
+			}
+		}
+		if ggufPath == "" {
+			return fmt.Errorf("no GGUF file given (use --gguf or a Modelfile FROM line)")
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		// Copy the weights into the blob store
+		gguf, err := os.Open(ggufPath)
+		if err != nil {
+			return fmt.Errorf("failed to open GGUF file: %w", err)
+		}
+		info, err := gguf.Stat()
+		if err != nil {
+			gguf.Close()
+			return fmt.Errorf("failed to stat GGUF file: %w", err)
+		}
+		bar := newProgressBar("Importing", info.Size(), quiet)
+		weights, err := writeBlob(modelPath, modelMediaType, io.TeeReader(gguf, bar))
+		gguf.Close()
+		bar.Finish()
+		if err != nil {
+			return err
+		}
+		layers := []Layer{weights}
+
+		// Add the Modelfile's text layers
+		for _, text := range []struct{ mediaType, value string }{
+			{templateMediaType, mf.Template},
+			{systemMediaType, mf.System},
+			{licenseMediaType, mf.License},
+		} {
+			if text.value == "" {
+				continue
+			}
+			layer, err := writeBlob(modelPath, text.mediaType, strings.NewReader(text.value))
+			if err != nil {
+				return err
+			}
+			layers = append(layers, layer)
+		}
+		if len(mf.Parameters) > 0 {
+			data, err := json.Marshal(mf.Parameters)
+			if err != nil {
+				return fmt.Errorf("failed to encode parameters: %w", err)
+			}
+			layer, err := writeBlob(modelPath, paramsMediaType, strings.NewReader(string(data)))
+			if err != nil {
+				return err
+			}
+			layers = append(layers, layer)
+		}
+
+		// Synthesize a minimal config blob referencing the layers
+		diffIDs := []string{}
+		for _, layer := range layers {
+			diffIDs = append(diffIDs, layer.Digest)
+		}
+		configData, err := json.Marshal(map[string]any{
+			"model_format": "gguf",
+			"architecture": runtime.GOARCH,
+			"os":           runtime.GOOS,
+			"rootfs":       map[string]any{"type": "layers", "diff_ids": diffIDs},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		config, err := writeBlob(modelPath, configMediaType, strings.NewReader(string(configData)))
+		if err != nil {
+			return err
+		}
+
+		manifestData, err := json.Marshal(Manifest{
+			SchemaVersion: manifestSchemaVersion,
+			MediaType:     manifestMediaType,
+			Config:        config,
+			Layers:        layers,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+
+		if err := writeManifest(modelPath, modelName, manifestData, importForce); err != nil {
+			return err
+		}
+
+		slog.Info("Imported model", "file", ggufPath, "model", modelName.ShortString())
+		return nil
+	},
+}
+
+var (
+	importGGUF      string
+	importModelfile string
+	importName      string
+	importForce     bool
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importGGUF, "gguf", "", "GGUF model file to import")
+	importCmd.Flags().StringVar(&importModelfile, "modelfile", "", "Modelfile with TEMPLATE, SYSTEM, LICENSE, and PARAMETER directives")
+	importCmd.Flags().StringVar(&importName, "name", "", "name of the imported model")
+	importCmd.Flags().BoolVarP(&importForce, "force", "f", false, "overwrite the model if it already exists")
+	importCmd.MarkFlagRequired("name")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"ollie/pkg/ollama"
+
+	"github.com/spf13/cobra"
+)
+
+// printModelConfig writes the fields of a config blob that describe the model,
+// skipping those it doesn't record, followed by a blank line if any were written
+func printModelConfig(w io.Writer, config *ollama.ModelConfig) {
+	family := config.ModelFamily
+	if len(config.ModelFamilies) > 0 && !slices.Equal(config.ModelFamilies, []string{family}) {
+		family = strings.TrimSpace(family + " (" + strings.Join(config.ModelFamilies, ", ") + ")")
+	}
+	platform := ""
+	if config.OS != "" || config.Architecture != "" {
+		platform = config.OS + "/" + config.Architecture
+	}
+	written := false
+	for _, field := range []struct{ label, value string }{
+		{"Family", family},
+		{"Parameters", config.ModelType},
+		{"Quantization", config.FileType},
+		{"Format", config.ModelFormat},
+		{"Platform", platform},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(w, "%-14s%s\n", field.label+":", field.value)
+			written = true
+		}
+	}
+	if written {
+		fmt.Fprintln(w)
+	}
+}
+
+// printBlobRow writes a single blob's digest, media type, and sizes to a tabwriter
+func printBlobRow(w io.Writer, modelPath, kind string, layer Layer) {
+	onDisk := "missing"
+	if info, err := os.Stat(filepath.Join(modelPath, "blobs", layer.BlobName())); err == nil {
+		onDisk = formatBytes(info.Size())
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", kind, layer.Digest, layer.MediaType, formatBytes(layer.Size), onDisk)
+}
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect MODEL_NAME",
+	Short: "Show a model's manifest and blob layout",
+	Long: `Inspect an Ollama model by reading its manifest from the models directory.
+Prints the config and layer digests, their media types, the sizes recorded
+in the manifest, and the size of each blob on disk.
+
+The config blob is read too, and the model details it records are shown:
+family, parameter count, quantization, and format. Use --config to print the
+whole config blob as indented JSON.
+
+Use --json to print the raw manifest instead, and --manifest to inspect a
+manifest file by path rather than by model name.
+
+Examples:
+  ollie inspect llama2
+  ollie inspect library/llama2:latest --json
+  ollie inspect llama2 --config
+  ollie inspect --manifest ~/.ollama/models/manifests/registry.ollama.ai/library/llama2/latest`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if inspectManifest != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var modelPath string
+		var modelName *ModelName
+		var err error
+
+		if inspectManifest != "" {
+			// Derive the model and models directory from the manifest's location
+			modelPath, modelName, err = modelFromManifestPath(inspectManifest)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Parse model name
+			modelName, err = parseModelName(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Get model path from environment or use default
+			modelPath, err = getOllamaModelsPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		manifestPath := filepath.Join(modelPath, manifestRelPath(modelName))
+
+		if inspectJSON {
+			data, err := os.ReadFile(manifestPath)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%w: no manifest at %s", ErrModelNotFound, manifestPath)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read manifest: %w", err)
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		manifest, err := readManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+
+		// An index has no config of its own, only its variants do
+		var configData []byte
+		var config *ollama.ModelConfig
+		if manifest.Config.Digest != "" {
+			if configData, config, err = readConfigBlob(modelPath, manifest.Config); err != nil {
+				if inspectConfig {
+					return err
+				}
+				slog.Warn("skipping model details", "error", err)
+			}
+		} else if inspectConfig {
+			return fmt.Errorf("%s has no config blob", modelName.ShortString())
+		}
+
+		if inspectConfig {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, configData, "", "  "); err != nil {
+				return fmt.Errorf("failed to format config blob: %w", err)
+			}
+			indented.WriteByte('\n')
+			_, err := indented.WriteTo(os.Stdout)
+			return err
+		}
+
+		fmt.Printf("Model:    %s\n", modelName)
+		fmt.Printf("Manifest: %s\n\n", manifestPath)
+		if config != nil {
+			printModelConfig(os.Stdout, config)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TYPE\tDIGEST\tMEDIA TYPE\tSIZE\tON DISK")
+		for _, entry := range manifest.Manifests {
+			printBlobRow(w, modelPath, "variant "+entry.Name(), entry.Layer())
+		}
+		if manifest.Config.Digest != "" {
+			printBlobRow(w, modelPath, "config", manifest.Config)
+		}
+		for _, layer := range manifest.Layers {
+			printBlobRow(w, modelPath, "layer", layer)
+		}
+		return w.Flush()
+	},
+}
+
+var (
+	inspectJSON     bool
+	inspectManifest string
+	inspectConfig   bool
+)
+
+func init() {
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "print the raw manifest JSON")
+	inspectCmd.Flags().BoolVar(&inspectConfig, "config", false, "print the config blob as indented JSON")
+	inspectCmd.MarkFlagsMutuallyExclusive("json", "config")
+	inspectCmd.Flags().StringVar(&inspectManifest, "manifest", "", "inspect the manifest at PATH instead of naming a model")
+	rootCmd.AddCommand(inspectCmd)
+}
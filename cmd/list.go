@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats for list
+const (
+	listFormatPlain = "plain"
+	listFormatTable = "table"
+	listFormatJSON  = "json"
+)
+
+// modelSummary describes a local model for list output
+type modelSummary struct {
+	Name     string    `json:"name"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Layers   int       `json:"layers"`
+	Modified time.Time `json:"modified"`
+}
+
+// summarizeModel reads a model's manifest and sums the on-disk size of its blobs.
+// The digest is the sha256 of the manifest file, which Ollama shows as the model ID.
+func summarizeModel(modelPath string, modelName *ModelName) (*modelSummary, error) {
+	manifestPath := filepath.Join(modelPath, manifestRelPath(modelName))
+
+	manifest, err := readManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat manifest: %w", err)
+	}
+	digest, err := fileSHA256(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash manifest: %w", err)
+	}
+
+	layers, err := parseManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	for _, layer := range layers {
+		if blob, err := os.Stat(filepath.Join(modelPath, "blobs", layer.BlobName())); err == nil {
+			size += blob.Size()
+		}
+	}
+
+	return &modelSummary{
+		Name:     modelName.ShortString(),
+		Digest:   "sha256:" + digest,
+		Size:     size,
+		Layers:   len(manifest.Layers),
+		Modified: info.ModTime(),
+	}, nil
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List local models",
+	Long: `List the models in the Ollama models directory.
+
+The --format flag selects the output:
+  plain  one model name per line (default, for piping into other commands)
+  table  aligned name, size, layer count, and modification time columns
+  json   an array of objects with name, digest, size, layers, and modified
+
+Sizes are the total size of each model's blobs on disk. The digest is the
+sha256 of the manifest, which Ollama shows as the model ID.
+
+Models matching a pattern in .ollieignore are left out. The file is read from
+the models directory and then the current directory, one glob per line, matched
+against the model name (e.g. "llama2:70b", "myorg/*", or "mixtral" for every
+tag). Later rules win, and a leading ! re-includes a model.
+
+Examples:
+  ollie list
+  ollie list --format table
+  ollie list --format json | jq '.[].name'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch listFormat {
+		case listFormatPlain, listFormatTable, listFormatJSON:
+		default:
+			return fmt.Errorf("unsupported format: %s (expected plain, table, or json)", listFormat)
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		models, err := listBulkModels(modelPath)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(models, func(a, b *ModelName) int {
+			return strings.Compare(a.ShortString(), b.ShortString())
+		})
+
+		if listFormat == listFormatPlain {
+			for _, model := range models {
+				fmt.Println(model.ShortString())
+			}
+			return nil
+		}
+
+		summaries := []*modelSummary{}
+		for _, model := range models {
+			summary, err := summarizeModel(modelPath, model)
+			if err != nil {
+				slog.Warn("skipping unreadable model", "model", model.ShortString(), "error", err)
+				continue
+			}
+			summaries = append(summaries, summary)
+		}
+
+		if listFormat == listFormatJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(summaries)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tLAYERS\tMODIFIED")
+		for _, summary := range summaries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", summary.Name, formatBytes(summary.Size), summary.Layers, summary.Modified.Format(time.DateTime))
+		}
+		return w.Flush()
+	},
+}
+
+var listFormat string
+
+func init() {
+	listCmd.Flags().StringVar(&listFormat, "format", listFormatPlain, "output format: plain, table, or json")
+	rootCmd.AddCommand(listCmd)
+}
@@ -2,61 +2,235 @@ package cmd
 
 import (
 	"archive/tar"
-	"compress/bzip2"
-	"compress/gzip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/ulikunitz/xz"
 )
 
-// extractTarball extracts a tarball to the specified destination directory
-func extractTarball(fileName, destPath string) error {
-	// Get ollama user/group ownership
-	uid, gid, err := getOllamaUIDGID()
+// extractOptions controls how extractTarball processes a tarball
+type extractOptions struct {
+	// Quiet suppresses progress output on stderr
+	Quiet bool
+	// Verbose logs each extracted file
+	Verbose bool
+	// Verify checks each blob's content against the digest in its file name
+	Verify bool
+	// Strict rejects entries outside manifests/ and blobs/sha256-<hex>
+	Strict bool
+	// UID and GID own extracted files and directories; -1 disables chown
+	UID, GID int
+	// NoClobberManifests keeps manifests that already exist instead of overwriting them
+	NoClobberManifests bool
+	// FileMode and DirMode, when non-zero, replace the modes recorded in the tarball
+	FileMode, DirMode os.FileMode
+	// ExpectedSHA256, when set, is checked against the raw input once it has been read
+	ExpectedSHA256 string
+	// Retag, when set, moves the tarball's manifest to this model name
+	Retag *ModelName
+	// Concurrency is the number of files verified and finished in parallel; 1 or
+	// less writes inline
+	Concurrency int
+	// Only, when set, limits extraction to entries matching one of these globs
+	Only []string
+	// PreserveXattrs restores extended attributes recorded in the tarball
+	PreserveXattrs bool
+}
+
+// isURL reports whether a load source names an HTTP(S) URL rather than a file
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// sourceFileName returns the file name part of a load source, dropping any
+// URL query so the extension can be used as a compression hint
+func sourceFileName(source string) string {
+	if isURL(source) {
+		if u, err := url.Parse(source); err == nil {
+			return u.Path
+		}
+	}
+	return source
+}
+
+// openLoadSource opens a tarball from a file, stdin ("-"), or an HTTP(S) URL,
+// returning its size in bytes when known and 0 otherwise
+func openLoadSource(ctx context.Context, source string) (io.ReadCloser, int64, error) {
+	if source == "-" {
+		info, err := os.Stdin.Stat()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to stat stdin: %w", err)
+		}
+		var size int64
+		if info.Mode().IsRegular() {
+			size = info.Size()
+		}
+		return io.NopCloser(os.Stdin), size, nil
+	}
+
+	if base, ok := splitBase(source); ok {
+		return openSplitParts(base)
+	}
+
+	if isURL(source) {
+		resp, err := getWithRetry(ctx, source, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, 0, fmt.Errorf("failed to download %s: server returned %s", source, resp.Status)
+		}
+		return resp.Body, max(resp.ContentLength, 0), nil
+	}
+
+	file, err := os.Open(source)
 	if err != nil {
-		slog.Warn("failed to get ollama UID/GID, proceeding without chown", "error", err)
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
 	}
-	// Open the tarball file
-	file, err := os.Open(fileName)
+	info, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to stat file: %w", err)
 	}
-	defer file.Close()
+	var size int64
+	if info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	return file, size, nil
+}
 
-	// Create the appropriate reader based on file extension
-	var tarReader *tar.Reader
+// decompressTarball wraps the raw tarball stream r with a decompressor for its
+// format. The format is detected from the stream's magic bytes, using the file
+// extension only as a hint to flag misnamed files. The input is buffered so the
+// magic bytes can be peeked without consuming them, which also makes detection
+// work for non-seekable streams such as stdin.
+func decompressTarball(r io.Reader, fileName string) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	compression := detectCompression(br)
+	if hint := compressionFromFileName(sourceFileName(fileName)); hint != compressNone && hint != compression {
+		slog.Warn("file extension doesn't match detected compression", "extension", hint, "detected", compression)
+	}
+	return newDecompressReader(br, compression)
+}
 
-	if strings.HasSuffix(fileName, ".tar.xz") {
-		xzReader, err := xz.NewReader(file)
-		if err != nil {
-			return fmt.Errorf("failed to create xz reader: %w", err)
+// listTarball writes the type, size, and name of each entry in a tarball to w
+// without extracting anything, followed by the total size. Only entries
+// matching the --only patterns are listed when any are given.
+func listTarball(ctx context.Context, w io.Writer, fileName string, only []string) error {
+	input, _, err := openLoadSource(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	decompressed, err := decompressTarball(&contextReader{ctx: ctx, r: input}, fileName)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	tarReader := tar.NewReader(decompressed)
+
+	var total int64
+	entries := 0
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
 		}
-		tarReader = tar.NewReader(xzReader)
-	} else if strings.HasSuffix(fileName, ".tar.gz") {
-		gzReader, err := gzip.NewReader(file)
 		if err != nil {
-			return fmt.Errorf("failed to create gzip reader: %w", err)
+			return fmt.Errorf("failed to read tar header: %w", err)
 		}
-		defer gzReader.Close()
-		tarReader = tar.NewReader(gzReader)
-	} else if strings.HasSuffix(fileName, ".tar.bz2") || strings.HasSuffix(fileName, ".tar.bz") {
-		bzReader := bzip2.NewReader(file)
-		tarReader = tar.NewReader(bzReader)
-	} else if strings.HasSuffix(fileName, ".tar") {
-		tarReader = tar.NewReader(file)
-	} else {
-		slog.Info("unrecognized file extension, assuming uncompressed tar")
-		tarReader = tar.NewReader(file)
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if len(only) > 0 && !matchesEntry(only, header.Name) {
+			continue
+		}
+
+		name := header.Name
+		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			name += " -> " + header.Linkname
+		}
+		fmt.Fprintf(w, "%-10s %12d  %s\n", entryTypeName(header.Typeflag), header.Size, name)
+		total += header.Size
+		entries++
+	}
+	fmt.Fprintf(w, "%-10s %12d  total (%d entries, %s)\n", "", total, entries, formatBytes(total))
+	return nil
+}
+
+// extractTarball extracts a tarball to the specified destination directory.
+// Canceling ctx stops extraction at the next read, and files still being
+// written are removed.
+func extractTarball(ctx context.Context, fileName, destPath string, opts extractOptions) error {
+	uid, gid := opts.UID, opts.GID
+
+	input, size, err := openLoadSource(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	// Track progress against the size of the (possibly compressed) input,
+	// since the uncompressed size isn't known until every header has been read.
+	// Pipes and responses without a length have no size, so only the byte count
+	// is shown for them.
+	bar := newProgressBar("Loading", size, opts.Quiet || opts.Verbose)
+	defer bar.Finish()
+	// Hash the raw input too when a checksum for the whole tarball is expected
+	inputHasher := sha256.New()
+	teed := io.TeeReader(&contextReader{ctx: ctx, r: input}, io.MultiWriter(bar, inputHasher))
+
+	decompressed, err := decompressTarball(teed, fileName)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	tarReader := tar.NewReader(decompressed)
+
+	// Lay out the models directory before any entry is written, so a fresh
+	// destination gets its top-level directories with the right mode and owner
+	for _, dir := range []string{"manifests", "blobs"} {
+		if err := mkdirAllOwned(filepath.Join(destPath, dir), uid, gid); err != nil {
+			return err
+		}
+	}
+
+	// Hand spooled files to workers to finish when extracting concurrently
+	var pool *writerPool
+	if opts.Concurrency > 1 {
+		pool = newWriterPool(opts.Concurrency, uid, gid, opts.FileMode)
+		defer pool.Wait()
 	}
 
 	// Extract files from the tarball
+	skipped := 0
+	dirHeaders := []*tar.Header{}
+	seen := map[string]bool{}
+	retagged := false
+	keptManifests := 0
+	matched := 0
 	for {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
 		header, err := tarReader.Next()
 		if err == io.EOF {
 			break // End of archive
@@ -65,91 +239,980 @@ func extractTarball(fileName, destPath string) error {
 			return fmt.Errorf("failed to read tar header: %w", err)
 		}
 
-		// Construct full path
-		targetPath := filepath.Join(destPath, header.Name)
+		// Only directories and regular files are extracted. The tar reader folds
+		// PAX and GNU long-name records into the header that follows them, and
+		// global PAX headers describe no file, so they're skipped.
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeReg:
+		case tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeSymlink:
+			return fmt.Errorf("unsupported symlink entry in tarball: %s -> %s (saved with --no-follow-symlinks? only regular files and directories can be loaded)", header.Name, header.Linkname)
+		default:
+			return fmt.Errorf("unsupported %s entry in tarball: %s (only regular files and directories can be loaded)", entryTypeName(header.Typeflag), header.Name)
+		}
+
+		if opts.Strict {
+			if err := validateEntryName(header.Name, header.Typeflag == tar.TypeDir); err != nil {
+				return err
+			}
+		}
+
+		// Extended attributes are only restored on request, since they may not
+		// apply on this system. writeEntry sets any that are left.
+		if !opts.PreserveXattrs {
+			for key := range header.PAXRecords {
+				if strings.HasPrefix(key, paxXattrPrefix) {
+					delete(header.PAXRecords, key)
+				}
+			}
+		}
+
+		// Leave out entries --only doesn't select
+		if len(opts.Only) > 0 {
+			if !matchesEntry(opts.Only, header.Name) {
+				continue
+			}
+			matched++
+		}
+
+		// Move the manifest to the new name, leaving blobs where they are
+		isManifest := header.Typeflag != tar.TypeDir && isManifestEntry(header.Name)
+		if opts.Retag != nil && isManifest {
+			if retagged {
+				return fmt.Errorf("--retag requires a tarball with a single manifest, found another: %s", header.Name)
+			}
+			retagged = true
+			header.Name = filepath.ToSlash(manifestRelPath(opts.Retag))
+		}
+
+		// Construct full path, refusing entries that escape the destination
+		targetPath, err := safeJoin(destPath, header.Name)
+		if err != nil {
+			return err
+		}
 
 		// Handle directory entries
 		if header.Typeflag == tar.TypeDir {
-			if err := os.MkdirAll(targetPath, os.ModePerm); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
+			if err := mkdirAllOwned(targetPath, uid, gid); err != nil {
+				return err
+			}
+			if opts.DirMode != 0 {
+				if err := os.Chmod(targetPath, opts.DirMode); err != nil {
+					return fmt.Errorf("failed to set mode for directory %s: %w", targetPath, err)
+				}
 			}
-			// Set ownership to ollama:ollama if user/group exists
-			if uid != -1 && gid != -1 {
-				if err := os.Chown(targetPath, uid, gid); err != nil {
+			// Set ownership if an owner was resolved
+			if uid != -1 || gid != -1 {
+				if err := chown(targetPath, uid, gid); err != nil {
 					slog.Warn("failed to set ownership for directory", "dir", targetPath, "error", err)
 				}
 			}
+			// Directory times are restored once all of their contents are written
+			dirHeaders = append(dirHeaders, header)
+			continue
+		}
+
+		// A well-formed tarball never repeats an entry. The first copy wins;
+		// when verifying, a repeated blob whose content differs is an error.
+		expected, isBlob := blobDigest(header.Name)
+		if seen[targetPath] {
+			if isBlob && opts.Verify {
+				hasher := sha256.New()
+				if _, err := io.Copy(hasher, tarReader); err != nil {
+					return fmt.Errorf("failed to read %s: %w", header.Name, err)
+				}
+				if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+					return fmt.Errorf("duplicate entry %s has conflicting content: expected sha256 %s, got %s", header.Name, expected, actual)
+				}
+			}
+			slog.Warn("skipping duplicate tarball entry", "name", header.Name)
+			continue
+		}
+		seen[targetPath] = true
+
+		// Leave existing manifests alone so an old tarball can't move a tag back
+		if isManifest && opts.NoClobberManifests {
+			if _, err := os.Lstat(targetPath); err == nil {
+				slog.Warn("keeping existing manifest", "name", header.Name)
+				keptManifests++
+				continue
+			}
+		}
+
+		// Blobs are content-addressed, so an existing blob of the right size
+		// (and digest, when verifying) never needs to be rewritten
+		if isBlob && blobExists(targetPath, header.Size, expected, opts.Verify) {
+			skipped++
+			if opts.Verbose {
+				slog.Info("Skipped blob already present", "name", header.Name, "size", formatBytes(header.Size))
+			}
+			bar.File(header.Name)
 			continue
 		}
 
+		if opts.Verbose {
+			slog.Info("Extracting file", "name", header.Name, "size", formatBytes(header.Size))
+		}
+		bar.File(header.Name)
+
+		// Files handed to the ollama user (or another owner) shouldn't stay
+		// writable by everyone else unless --chmod asks for it
+		if opts.FileMode == 0 && (uid != -1 || gid != -1) {
+			header.Mode &^= 0o022
+		}
+
 		// Create parent directories for files
 		parentDir := filepath.Dir(targetPath)
-		if err := os.MkdirAll(parentDir, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create parent directory for %s: %w", targetPath, err)
+		if err := mkdirAllOwned(parentDir, uid, gid); err != nil {
+			return err
+		}
+		if opts.DirMode != 0 {
+			if err := os.Chmod(parentDir, opts.DirMode); err != nil {
+				return fmt.Errorf("failed to set mode for directory %s: %w", parentDir, err)
+			}
 		}
 		// Set ownership on parent directory
-		if uid != -1 && gid != -1 {
-			if err := os.Chown(parentDir, uid, gid); err != nil {
+		if uid != -1 || gid != -1 {
+			if err := chown(parentDir, uid, gid); err != nil {
 				return fmt.Errorf("failed to set ownership for parent directory %s: %w", parentDir, err)
 			}
 		}
 
-		// Create and write file
-		outFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
-		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", targetPath, err)
+		// Write the file inline, or spool it and hand it to a worker
+		if pool == nil {
+			if err := writeEntry(tarReader, targetPath, header, isBlob && opts.Verify, uid, gid, opts.FileMode); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := pool.Submit(tarReader, targetPath, header, isBlob && opts.Verify); err != nil {
+			return err
 		}
+	}
 
-		if _, err := io.Copy(outFile, tarReader); err != nil {
-			outFile.Close()
-			return fmt.Errorf("failed to write file %s: %w", targetPath, err)
+	// Wait for any outstanding writes before touching directory times
+	if pool != nil {
+		if err := pool.Wait(); err != nil {
+			return err
 		}
+	}
+
+	// Restore directory times deepest first, after their contents are in place
+	for i := len(dirHeaders) - 1; i >= 0; i-- {
+		header := dirHeaders[i]
+		targetPath := filepath.Join(destPath, header.Name)
+		if err := os.Chtimes(targetPath, header.AccessTime, header.ModTime); err != nil {
+			slog.Warn("failed to set times for directory", "dir", targetPath, "error", err)
+		}
+	}
+
+	// Streams can only be checked once they've been read to the end, so the
+	// decompressor is stopped and whatever follows the archive is hashed too
+	if opts.ExpectedSHA256 != "" {
+		decompressed.Close()
+		if _, err := io.Copy(io.Discard, teed); err != nil {
+			return fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if actual := hex.EncodeToString(inputHasher.Sum(nil)); actual != opts.ExpectedSHA256 {
+			return fmt.Errorf("tarball failed verification after extraction: expected sha256 %s, got %s", opts.ExpectedSHA256, actual)
+		}
+	}
+
+	bar.Finish()
+	if len(opts.Only) > 0 && matched == 0 {
+		return fmt.Errorf("no entries in the tarball match --only %s", strings.Join(opts.Only, ", "))
+	}
+	if skipped > 0 {
+		slog.Info("Skipped blobs already present", "blobs", skipped)
+	}
+	if keptManifests > 0 {
+		slog.Info("Kept existing manifests", "manifests", keptManifests)
+	}
+
+	return nil
+}
+
+// extractDirMode is the mode of directories created during extraction, before
+// the umask and unless --chmod sets one
+const extractDirMode = 0o755
+
+// mkdirAllOwned creates dir and any missing parents with extractDirMode, giving
+// each directory it creates to uid and gid (when not -1). Directories that
+// already exist are left as they are.
+func mkdirAllOwned(dir string, uid, gid int) error {
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("failed to create directory %s: a file is in the way", dir)
+		}
+		return nil
+	}
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := mkdirAllOwned(parent, uid, gid); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, extractDirMode); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	if uid != -1 || gid != -1 {
+		if err := chown(dir, uid, gid); err != nil {
+			slog.Warn("failed to set ownership for directory", "dir", dir, "error", err)
+		}
+	}
+	return nil
+}
+
+// partialSuffix marks files that are still being written during extraction
+const partialSuffix = ".partial"
+
+// writeEntry writes the content of a tar entry from r to targetPath, then applies
+// ownership and times from the header. The content is written to a .partial file
+// that is only renamed into place once complete, so an interrupted or failed write
+// never leaves a truncated file under its final name. When verify is set, the
+// content must hash to the digest in the blob's name. A non-zero mode is applied
+// exactly, regardless of the umask.
+func writeEntry(r io.Reader, targetPath string, header *tar.Header, verify bool, uid, gid int, mode os.FileMode) error {
+	partialPath := targetPath + partialSuffix
+	actual, err := spoolEntry(r, partialPath, targetPath, header, verify)
+	if err != nil {
+		return err
+	}
+	return finishEntry(partialPath, targetPath, header, verify, actual, uid, gid, mode)
+}
+
+// spoolEntry writes the content of a tar entry from r to partialPath. When withSum
+// is set, it returns the content's sha256, hashed while it streams to disk.
+// The file is removed if writing fails.
+func spoolEntry(r io.Reader, partialPath, targetPath string, header *tar.Header, withSum bool) (sum string, err error) {
+	defer func() {
+		if err != nil {
+			os.Remove(partialPath)
+		}
+	}()
+
+	outFile, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
+	if err != nil {
+		return "", fmt.Errorf("failed to create file %s: %w", targetPath, err)
+	}
+
+	src := r
+	var hasher hash.Hash
+	if withSum {
+		hasher = sha256.New()
+		src = io.TeeReader(r, hasher)
+	}
+
+	if _, err := io.Copy(outFile, src); err != nil {
 		outFile.Close()
+		return "", fmt.Errorf("failed to write file %s: %w", targetPath, err)
+	}
+	if err := outFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %w", targetPath, err)
+	}
+	if hasher == nil {
+		return "", nil
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
 
-		// Set ownership on the file
-		if uid != -1 && gid != -1 {
-			if err := os.Chown(targetPath, uid, gid); err != nil {
-				slog.Warn("failed to set ownership for file", "file", targetPath, "error", err)
+// finishEntry checks a spooled entry against the digest in its blob name when
+// verify is set, applies the mode, ownership, xattrs, and times from the
+// header, and renames it into place. The file is removed if any step fails.
+func finishEntry(partialPath, targetPath string, header *tar.Header, verify bool, actual string, uid, gid int, mode os.FileMode) (err error) {
+	defer func() {
+		if err != nil {
+			os.Remove(partialPath)
+		}
+	}()
+
+	if verify {
+		expected, _ := blobDigest(header.Name)
+		if actual != expected {
+			return fmt.Errorf("blob %s failed verification: expected sha256 %s, got %s", header.Name, expected, actual)
+		}
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(partialPath, mode); err != nil {
+			return fmt.Errorf("failed to set mode for file %s: %w", targetPath, err)
+		}
+	}
+
+	// Set ownership on the file
+	if uid != -1 || gid != -1 {
+		if err := chown(partialPath, uid, gid); err != nil {
+			slog.Warn("failed to set ownership for file", "file", targetPath, "error", err)
+		}
+	}
+
+	if err := writeXattrs(partialPath, header.PAXRecords); err != nil {
+		slog.Warn("failed to restore extended attributes", "file", targetPath, "error", err)
+	}
+
+	// Preserve the modification time recorded in the tarball
+	if err := os.Chtimes(partialPath, header.AccessTime, header.ModTime); err != nil {
+		slog.Warn("failed to set times for file", "file", targetPath, "error", err)
+	}
+
+	if err := os.Rename(partialPath, targetPath); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", targetPath, err)
+	}
+
+	return nil
+}
+
+// blobExists reports whether a blob already exists at path with the given size.
+// When verify is set, its content must also hash to the expected digest.
+func blobExists(path string, size int64, expected string, verify bool) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != size {
+		return false
+	}
+	if !verify {
+		return true
+	}
+	actual, err := fileSHA256(path)
+	return err == nil && actual == expected
+}
+
+// scanTarballSize sums the sizes of the entries an uncompressed tarball file would
+// write to destPath, leaving out blobs that are already present. It reports false
+// for stdin, URLs, and compressed files, which can't be scanned without reading
+// (and decompressing) the whole stream twice.
+func scanTarballSize(fileName, destPath string) (int64, bool, error) {
+	if _, split := splitBase(fileName); fileName == "-" || isURL(fileName) || split {
+		return 0, false, nil
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if !info.Mode().IsRegular() || detectCompression(bufio.NewReader(file)) != compressNone {
+		return 0, false, nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, false, fmt.Errorf("failed to rewind file: %w", err)
+	}
+
+	// The tar reader seeks past entry contents, so only headers are read
+	var total int64
+	tarReader := tar.NewReader(file)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if expected, isBlob := blobDigest(header.Name); isBlob {
+			if blobExists(filepath.Join(destPath, header.Name), header.Size, expected, false) {
+				continue
 			}
 		}
+		total += header.Size
 	}
 
+	return total, true, nil
+}
+
+// checkFreeSpace returns an error when the filesystem holding destPath has less
+// than needed bytes available. Failing to query free space only logs a warning.
+func checkFreeSpace(destPath string, needed int64) error {
+	available, err := freeSpace(destPath)
+	if err != nil {
+		slog.Warn("failed to check free disk space, proceeding anyway", "path", destPath, "error", err)
+		return nil
+	}
+	if needed > available {
+		return fmt.Errorf("not enough disk space in %s: loading needs %s but only %s is available", destPath, formatBytes(needed), formatBytes(available))
+	}
 	return nil
 }
 
+// entryTypeName describes a tar entry type for error messages
+func entryTypeName(typeflag byte) string {
+	switch typeflag {
+	case tar.TypeReg:
+		return "file"
+	case tar.TypeDir:
+		return "directory"
+	case tar.TypeLink:
+		return "hard link"
+	case tar.TypeSymlink:
+		return "symlink"
+	case tar.TypeChar:
+		return "character device"
+	case tar.TypeBlock:
+		return "block device"
+	case tar.TypeFifo:
+		return "FIFO"
+	default:
+		return fmt.Sprintf("type %q", typeflag)
+	}
+}
+
+// parseChmod parses a --chmod value: FILEMODE,DIRMODE in octal, or a single
+// mode for files whose directories get execute bits wherever it grants read
+func parseChmod(spec string) (os.FileMode, os.FileMode, error) {
+	parse := func(s string) (os.FileMode, error) {
+		mode, err := strconv.ParseUint(s, 8, 32)
+		if err != nil || mode == 0 || mode > 0o777 {
+			return 0, fmt.Errorf("invalid --chmod mode %q (expected octal such as 0644)", s)
+		}
+		return os.FileMode(mode), nil
+	}
+
+	fileSpec, dirSpec, hasDir := strings.Cut(spec, ",")
+	fileMode, err := parse(fileSpec)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !hasDir {
+		return fileMode, fileMode | (fileMode&0o444)>>2, nil
+	}
+	dirMode, err := parse(dirSpec)
+	if err != nil {
+		return 0, 0, err
+	}
+	return fileMode, dirMode, nil
+}
+
+// resolveLoadOwner returns the UID and GID to give extracted files. Explicit
+// IDs (uid and gid when uidSet and gidSet, or a --numeric-owner spec) win over
+// owner and the ollama lookup, and when both IDs are given the lookup is
+// skipped entirely, since the user may not exist where ollie runs. A failed
+// ollama lookup disables chown rather than failing the load.
+func resolveLoadOwner(owner, numericOwner string, uid, gid int, uidSet, gidSet bool) (int, int, error) {
+	if numericOwner != "" {
+		var err error
+		if uid, gid, err = parseNumericOwner(numericOwner); err != nil {
+			return -1, -1, err
+		}
+		uidSet, gidSet = true, true
+	}
+	if !uidSet || !gidSet {
+		ownerUID, ownerGID, err := resolveOwner(owner)
+		if err != nil {
+			if owner != "" {
+				return -1, -1, err
+			}
+			slog.Warn("failed to get ollama UID/GID, proceeding without chown", "error", err)
+			ownerUID, ownerGID = -1, -1
+		}
+		if !uidSet {
+			uid = ownerUID
+		}
+		if !gidSet {
+			gid = ownerGID
+		}
+	}
+	if uid < -1 || gid < -1 {
+		return -1, -1, fmt.Errorf("invalid --uid/--gid: IDs must be -1 or greater")
+	}
+	return uid, gid, nil
+}
+
+// expectedTarballSHA256 returns the sha256 the whole tarball should have: the
+// --expected-sha256 value, or the digest in a FILE.sha256 sidecar written by
+// save --checksum. It returns "" when there is nothing to check against.
+func expectedTarballSHA256(fileName, flagValue string) (string, error) {
+	if flagValue != "" {
+		sum := strings.ToLower(strings.TrimPrefix(flagValue, "sha256:"))
+		if !sha256HexPattern.MatchString(sum) {
+			return "", fmt.Errorf("invalid --expected-sha256 %q: expected 64 hex characters", flagValue)
+		}
+		return sum, nil
+	}
+	if fileName == "-" || isURL(fileName) {
+		return "", nil
+	}
+	// save --split --checksum hashes the whole stream into BASE.sha256
+	if base, ok := splitBase(fileName); ok {
+		fileName = base
+	}
+
+	data, err := os.ReadFile(fileName + ".sha256")
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksum file: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || !sha256HexPattern.MatchString(strings.ToLower(fields[0])) {
+		return "", fmt.Errorf("checksum file %s.sha256 doesn't start with a sha256 digest", fileName)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// errTarballMismatch is returned when a tarball doesn't have its expected sha256
+var errTarballMismatch = errors.New("tarball failed verification")
+
+// verifyTarballFile checks a tarball that can be read twice against
+// expectedSum before anything is loaded from it. It returns the sum still to
+// be checked as the tarball is read: "" once checked, or expectedSum for
+// stdin, URLs, and split sets.
+func verifyTarballFile(fileName, expectedSum string) (string, error) {
+	if expectedSum == "" || fileName == "-" {
+		return expectedSum, nil
+	}
+	if _, split := splitBase(fileName); split {
+		return expectedSum, nil
+	}
+	if info, err := os.Stat(fileName); err != nil || !info.Mode().IsRegular() {
+		return expectedSum, nil
+	}
+	actual, err := fileSHA256(fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash tarball: %w", err)
+	}
+	if actual != expectedSum {
+		return "", fmt.Errorf("%w: expected sha256 %s, got %s", errTarballMismatch, expectedSum, actual)
+	}
+	return "", nil
+}
+
+// sha256HexPattern matches a hex-encoded sha256 digest
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// matchesEntry reports whether a tar entry name, or one of its parent
+// directories, matches any of the glob patterns, so "manifests" selects every
+// manifest and "blobs/sha256-ab*" a single blob
+func matchesEntry(patterns []string, name string) bool {
+	name = path.Clean(filepath.ToSlash(name))
+	for p := name; p != "." && p != "/"; p = path.Dir(p) {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paxXattrPrefix marks the PAX records that hold a file's extended attributes
+const paxXattrPrefix = "SCHILY.xattr."
+
+// isManifestEntry reports whether a tar entry name is under manifests/
+func isManifestEntry(name string) bool {
+	return strings.HasPrefix(path.Clean(filepath.ToSlash(name)), "manifests/")
+}
+
+// blobDigest returns the hex sha256 digest encoded in a blob entry name
+// such as blobs/sha256-<hex>, and whether the name refers to a blob at all
+func blobDigest(name string) (string, bool) {
+	dir, base := path.Split(filepath.ToSlash(name))
+	if path.Clean(dir) != "blobs" || !strings.HasPrefix(base, "sha256-") {
+		return "", false
+	}
+	return strings.TrimPrefix(base, "sha256-"), true
+}
+
+// blobNamePattern matches the file name of a content-addressed blob
+var blobNamePattern = regexp.MustCompile(`^sha256-[0-9a-f]{64}$`)
+
+// validateEntryName checks that a tar entry belongs in an Ollama models directory:
+// manifests live under manifests/ and blobs are named blobs/sha256-<hex>
+func validateEntryName(name string, isDir bool) error {
+	clean := path.Clean(filepath.ToSlash(name))
+	if isDir && (clean == "." || clean == "manifests" || clean == "blobs") {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(clean, "manifests/"):
+		return nil
+	case strings.HasPrefix(clean, "blobs/") && !isDir:
+		if !blobNamePattern.MatchString(strings.TrimPrefix(clean, "blobs/")) {
+			return fmt.Errorf("unexpected blob in tarball: %s (expected blobs/sha256-<hex>)", name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected entry in tarball: %s (expected only manifests/ and blobs/)", name)
+	}
+}
+
+// safeJoin joins name onto destPath, rejecting absolute names and ensuring the
+// result stays inside destPath
+func safeJoin(destPath, name string) (string, error) {
+	// Tar entry names are always relative. Check both separators and drive
+	// letters explicitly so a tarball is rejected the same way on every platform.
+	if strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) || filepath.IsAbs(name) ||
+		filepath.VolumeName(name) != "" || (len(name) >= 2 && name[1] == ':') {
+		return "", fmt.Errorf("illegal path in tarball: %s is absolute", name)
+	}
+
+	targetPath := filepath.Join(destPath, name)
+
+	rel, err := filepath.Rel(filepath.Clean(destPath), targetPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal path in tarball: %s escapes destination directory", name)
+	}
+
+	return targetPath, nil
+}
+
 var loadCmd = &cobra.Command{
-	Use:   "load TARBALL_FILE",
+	Use:   "load TARBALL_FILE|URL|-",
 	Short: "Load an Ollama model from a tarball",
 	Long: `Load an Ollama model by extracting a tarball to the Ollama models directory.
-Supports .tar, .tar.gz, .tar.bz/.tar.bz2, and .tar.xz formats.
+Supports .tar, .tar.gz, .tar.bz/.tar.bz2, .tar.xz, and .tar.zst formats. The
+compression format is detected from the file's contents, so misnamed files load
+correctly.
+
+Pass - as the file name to read the tarball from stdin. The stream is buffered
+so its compression format can be sniffed before extraction starts.
+A file name ending in .001 is the first part of a tarball written with
+save --split; the following parts (.002, .003, ...) are read in order as one
+stream, and a gap in the numbering is reported as a missing part.
+An http:// or https:// URL is downloaded and extracted as it streams in;
+redirects are followed, and any status other than 200 OK is an error.
+With --resume, the URL is instead downloaded to a file in a private ollie
+directory under the user cache directory first and extracted from there. If a
+download is interrupted, running the same command again continues it with an
+HTTP Range request, or restarts it when the server doesn't support ranges. The
+file is removed once the load succeeds, and its sha256 is checked against
+--expected-sha256 before anything is extracted. A finished download left by a
+failed load is only reused if it matches --expected-sha256; otherwise it's
+downloaded again.
+
+Connection errors and 5xx responses are retried up to --retries times, waiting
+--retry-delay before the first retry and twice as long before each one after.
+With --resume, a download that's cut off partway is also retried, continuing
+where it stopped.
+
+The tarball is extracted to the models directory given by --models-path,
+OLLIE_MODELS, or OLLAMA_MODELS, or the default models directory if none is set
+(see "ollie --help"). Use --dest to extract to a different directory instead.
+
+Only manifests/ and blobs/sha256-<hex> entries are accepted; pass
+--strict=false to extract other entries as well. Entries must be regular files
+or directories: symlinks, such as those written by save --no-follow-symlinks,
+are rejected rather than created.
+
+Extracted files are owned by the ollama user and group when they exist.
+Use --owner to choose a different user:group (names or numeric IDs), or
+--owner none to leave ownership unchanged. --uid and --gid set numeric IDs
+directly and take precedence over --owner, which helps when loading inside a
+container whose users don't match the host's; -1 leaves that ID unchanged.
+--numeric-owner UID:GID forces both IDs at once without looking up any user,
+for minimal images where the ollama user doesn't exist but the server runs
+under a known UID.
+
+File modes come from the tarball, except that group and other write bits are
+dropped when files are chowned. Use --chmod to set modes explicitly: 0644,0755
+sets files and directories separately, while a single mode such as 0640 applies
+to files and adds execute bits to directories wherever it grants read.
+Modification times also come from the tarball, so "ollie prune --older-than"
+sees a loaded model as being as old as its archived manifest.
+
+Blobs are content-addressed, so overwriting them is always safe, but loading
+an older tarball overwrites a tag's manifest with an older one. Pass
+--no-clobber-manifests to keep existing manifests; they're reported and skipped.
+
+Use --retag NEWNAME to load a single-model tarball under a different name, e.g.
+to give a model pulled from registry.ollama.ai a name in your own namespace.
+Only the manifest's location changes; blobs are extracted as usual.
 
-The tarball is extracted to the directory specified by the OLLAMA_MODELS
-environment variable, or ~/.ollama/models if not set.
+Before extracting, the destination is checked for enough free space. For
+uncompressed tarball files the space needed is summed from the tar headers,
+leaving out blobs that are already present. Compressed files, URLs, and stdin
+can't be scanned up front, so pass their uncompressed size with --expected-size
+to check those.
+
+The whole tarball is checked against --expected-sha256, or against the digest
+in a FILE.sha256 file next to it such as save --checksum writes. Files are
+hashed before anything is extracted; stdin and URLs can only be checked after
+extraction, and the load fails if they don't match.
+
+Use --only PATTERN (repeatable) to extract just the entries whose name, or a
+parent directory of it, matches a glob, e.g. to restore a single missing blob.
+Combine it with --dest to extract into a scratch directory.
+
+Use --via-api URL to load into a running Ollama server through its HTTP API
+instead of the models directory, avoiding file ownership and restart concerns.
+Each blob the server doesn't already have is uploaded to /api/blobs, and each
+manifest's model is then created with /api/create from its GGUF, adapter,
+template, system, parameters, messages, and license layers. The checksum is
+checked the same way: files before anything is uploaded, and stdin and URLs
+before any model is created.
+
+Use --list to preview a tarball before loading it: each entry's type, size,
+and name is printed to stdout, followed by the total, and nothing is written.
+This reads the whole (decompressed) stream, so it works for URLs, stdin, and
+split tarballs too, but the checksum isn't verified. Entries that load would
+reject, such as symlinks or names outside manifests/ and blobs/, are listed
+as they are. --only limits the listing to matching entries.
+
+Extended attributes recorded by save --preserve-xattrs are restored with
+--preserve-xattrs on Linux, and ignored otherwise.
+
+With --verify, each blob is hashed while it is extracted and the load is
+aborted if its content doesn't match the digest in its file name.
+
+Interrupting a load (Ctrl-C) or exceeding --timeout stops it cleanly: files
+still being written are removed, and blobs already extracted are kept.
 
 Examples:
   ollie load llama2.tar
+  ollie load --list llama2.tar.gz
+  ollie load --verify llama2.tar
   ollie load llama2.tar.gz
-  ollie load llama2.tar.xz`,
+  ollie load llama2.tar.xz
+  ollie load llama2.tar.001
+  ollie load --dest /tmp/staging llama2.tar
+  ollie save llama2 | ssh host ollie load -
+  ollie load --verify https://models.example.com/llama2.tar.zst
+  ollie load --resume --expected-sha256 <sum> https://models.example.com/llama2.tar.zst
+  ollie load --owner 1000:1000 llama2.tar
+  ollie load --chmod 0644,0755 llama2.tar
+  ollie load --uid 999 --gid 999 llama2.tar
+  ollie load --numeric-owner 999:999 llama2.tar
+  ollie load --retag myregistry/team/llama2:prod llama2.tar
+  ollie load --only 'blobs/sha256-8934d96d*' llama2.tar
+  ollie load --only manifests --dest /tmp/scratch llama2.tar
+  ollie load --via-api http://localhost:11434 llama2.tar`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fileName := args[0]
 
-		// Get model path from environment or use default
-		modelPath, err := getOllamaModelsPath()
+		// Stop cleanly on interrupt or once --timeout elapses
+		ctx, cancel := commandContext(cmd.Context(), loadTimeout)
+		defer cancel()
+
+		// Only show what's in the tarball
+		if loadList {
+			for _, pattern := range loadOnly {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("invalid --only pattern %q: %w", pattern, err)
+				}
+			}
+			return listTarball(ctx, os.Stdout, fileName, loadOnly)
+		}
+
+		// Hand the model to a running server instead of writing files
+		if loadViaAPIURL != "" {
+			var retag *ModelName
+			if loadRetag != "" {
+				var err error
+				if retag, err = parseModelName(loadRetag); err != nil {
+					return err
+				}
+			}
+			// Check the tarball before uploading anything when it can be read twice
+			expectedSum, err := expectedTarballSHA256(fileName, loadExpectedSHA256)
+			if err != nil {
+				return err
+			}
+			if expectedSum, err = verifyTarballFile(fileName, expectedSum); err != nil {
+				return err
+			}
+			return loadViaAPI(ctx, fileName, loadViaAPIURL, retag, expectedSum, quiet || verbose)
+		}
+
+		// Use the explicit destination, or the model path from environment or default
+		modelPath := loadDest
+		if modelPath == "" {
+			var err error
+			modelPath, err = getOllamaModelsPath()
+			if err != nil {
+				return err
+			}
+		} else if err := os.MkdirAll(modelPath, extractDirMode); err != nil {
+			return fmt.Errorf("failed to create destination directory %s: %w", modelPath, err)
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		expectedSum, err := expectedTarballSHA256(fileName, loadExpectedSHA256)
+		if err != nil {
+			return err
+		}
+
+		// Download the whole tarball first when resuming, since extraction
+		// itself can't pick up where it left off
+		source := fileName
+		if loadResume {
+			if !isURL(fileName) {
+				return fmt.Errorf("--resume only applies to http(s) URLs")
+			}
+			// A download cut off partway is resumed, counting toward --retries
+			downloaded, err := downloadResumable(ctx, fileName, expectedSum, quiet || verbose)
+			for attempt := 1; errors.Is(err, errDownloadInterrupted) && attempt <= loadRetries; attempt++ {
+				delay := retryDelay(attempt)
+				slog.Warn("download interrupted, resuming", "attempt", attempt, "retries", loadRetries, "delay", delay, "error", err)
+				if err := sleepContext(ctx, delay); err != nil {
+					return err
+				}
+				downloaded, err = downloadResumable(ctx, fileName, expectedSum, quiet || verbose)
+			}
+			if err != nil {
+				return err
+			}
+			fileName = downloaded
+		}
+
+		// Resolve ownership of extracted files
+		uid, gid, err := resolveLoadOwner(loadOwner, loadNumericOwner, loadUID, loadGID, cmd.Flags().Changed("uid"), cmd.Flags().Changed("gid"))
 		if err != nil {
 			return err
 		}
 
+		// Check the whole tarball before extracting when it can be read twice;
+		// streams are checked as they're read instead
+		if expectedSum, err = verifyTarballFile(fileName, expectedSum); err != nil {
+			// Don't leave a bad download behind for the next --resume
+			if loadResume && errors.Is(err, errTarballMismatch) {
+				os.Remove(fileName)
+			}
+			return err
+		}
+
+		// Make sure the extracted files will fit before writing any of them
+		needed, known, err := scanTarballSize(fileName, modelPath)
+		if err != nil {
+			return err
+		}
+		if !known && loadExpectedSize > 0 {
+			needed, known = loadExpectedSize, true
+		}
+		if known {
+			if err := checkFreeSpace(modelPath, needed); err != nil {
+				return err
+			}
+		}
+
+		var fileMode, dirMode os.FileMode
+		if loadChmod != "" {
+			if fileMode, dirMode, err = parseChmod(loadChmod); err != nil {
+				return err
+			}
+		}
+
+		for _, pattern := range loadOnly {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --only pattern %q: %w", pattern, err)
+			}
+		}
+
+		var retag *ModelName
+		if loadRetag != "" {
+			if retag, err = parseModelName(loadRetag); err != nil {
+				return err
+			}
+		}
+
 		// Extract tarball
-		if err := extractTarball(fileName, modelPath); err != nil {
+		opts := extractOptions{
+			Quiet:              quiet,
+			Verbose:            verbose,
+			Verify:             loadVerify,
+			Strict:             loadStrict,
+			UID:                uid,
+			GID:                gid,
+			Retag:              retag,
+			ExpectedSHA256:     expectedSum,
+			FileMode:           fileMode,
+			DirMode:            dirMode,
+			NoClobberManifests: loadNoClobberManifests,
+			Concurrency:        loadConcurrency,
+			Only:               loadOnly,
+			PreserveXattrs:     loadPreserveXattrs,
+		}
+		if err := extractTarball(ctx, fileName, modelPath, opts); err != nil {
+			// A split tarball that ends early is most likely missing its last part
+			if base, split := splitBase(fileName); split && errors.Is(err, io.ErrUnexpectedEOF) {
+				parts, _ := existingParts(base)
+				return fmt.Errorf("%w (is part %s of the split tarball missing?)", err, partName(base, len(parts)+1))
+			}
 			return err
 		}
 
-		fmt.Fprintf(os.Stderr, "Successfully loaded model from %s to %s\n", fileName, modelPath)
+		if loadResume {
+			if err := os.Remove(fileName); err != nil {
+				slog.Warn("failed to remove downloaded tarball", "path", fileName, "error", err)
+			}
+		}
+
+		if source == "-" {
+			source = "stdin"
+		}
+		slog.Info("Successfully loaded model", "source", source, "dest", modelPath)
 		return nil
 	},
 }
 
+var (
+	loadVerify             bool
+	loadDest               string
+	loadStrict             bool
+	loadOwner              string
+	loadConcurrency        int
+	loadRetag              string
+	loadExpectedSize       int64
+	loadNoClobberManifests bool
+	loadChmod              string
+	loadUID                int
+	loadExpectedSHA256     string
+	loadGID                int
+	loadResume             bool
+	loadOnly               []string
+	loadRetries            int
+	loadRetryDelay         time.Duration
+	loadViaAPIURL          string
+	loadPreserveXattrs     bool
+	loadTimeout            time.Duration
+	loadNumericOwner       string
+	loadList               bool
+)
+
 func init() {
+	loadCmd.Flags().StringVar(&loadDest, "dest", "", "extract to DIR instead of the Ollama models directory")
+	loadCmd.Flags().BoolVar(&loadVerify, "verify", false, "verify each blob's sha256 digest while extracting")
+	loadCmd.Flags().BoolVar(&loadStrict, "strict", true, "reject entries other than manifests/ and blobs/sha256-<hex>")
+	loadCmd.Flags().StringVar(&loadOwner, "owner", "", "owner of extracted files as user:group, uid:gid, or none (default ollama:ollama if it exists)")
+	loadCmd.Flags().BoolVar(&loadNoClobberManifests, "no-clobber-manifests", false, "keep manifests that already exist instead of overwriting them")
+	loadCmd.Flags().StringVar(&loadExpectedSHA256, "expected-sha256", "", "sha256 the whole tarball must have (default: read from FILE.sha256 if present)")
+	loadCmd.Flags().IntVar(&loadUID, "uid", -1, "numeric owner of extracted files, overriding --owner (-1 leaves the owner unchanged)")
+	loadCmd.Flags().IntVar(&loadGID, "gid", -1, "numeric group of extracted files, overriding --owner (-1 leaves the group unchanged)")
+	loadCmd.Flags().StringVar(&loadNumericOwner, "numeric-owner", "", "force ownership of extracted files to UID:GID without looking up users")
+	for _, flag := range []string{"owner", "uid", "gid"} {
+		loadCmd.MarkFlagsMutuallyExclusive("numeric-owner", flag)
+	}
+	loadCmd.Flags().StringVar(&loadChmod, "chmod", "", "set extracted file and directory modes as FILEMODE[,DIRMODE] in octal, e.g. 0644,0755")
+	loadCmd.Flags().StringVar(&loadRetag, "retag", "", "load the tarball's manifest as NEWNAME instead of its saved name")
+	loadCmd.Flags().Int64Var(&loadExpectedSize, "expected-size", 0, "uncompressed size in bytes to check free space against when the source can't be scanned")
+	loadCmd.Flags().BoolVar(&loadResume, "resume", false, "download a URL to the user cache directory first, resuming an interrupted earlier download")
+	loadCmd.Flags().StringArrayVar(&loadOnly, "only", nil, "extract only entries matching this glob, e.g. blobs/sha256-abc* (repeatable)")
+	loadCmd.Flags().IntVar(&loadRetries, "retries", 3, "times to retry a URL download after connection errors or 5xx responses")
+	loadCmd.Flags().DurationVar(&loadRetryDelay, "retry-delay", time.Second, "delay before the first retry, doubling after each attempt")
+	loadCmd.Flags().StringVar(&loadViaAPIURL, "via-api", "", "upload the models to the running Ollama server at URL instead of writing files")
+	for _, flag := range []string{"dest", "owner", "uid", "gid", "numeric-owner", "chmod", "only", "no-clobber-manifests", "resume"} {
+		loadCmd.MarkFlagsMutuallyExclusive("via-api", flag)
+	}
+	loadCmd.Flags().BoolVar(&loadPreserveXattrs, "preserve-xattrs", false, "restore extended attributes recorded by save --preserve-xattrs (Linux only)")
+	loadCmd.Flags().DurationVar(&loadTimeout, "timeout", 0, "abort the load if it takes longer than this, e.g. 30m (0 means no limit)")
+	loadCmd.Flags().IntVar(&loadConcurrency, "concurrency", 1, "number of extracted files to verify and finish in parallel")
+	loadCmd.Flags().BoolVar(&loadList, "list", false, "list the tarball's entries without extracting anything")
+	for _, flag := range []string{"via-api", "dest", "resume", "retag", "verify"} {
+		loadCmd.MarkFlagsMutuallyExclusive("list", flag)
+	}
 	rootCmd.AddCommand(loadCmd)
 }
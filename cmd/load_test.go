@@ -0,0 +1,445 @@
+package cmd
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEntry is a tar entry written by writeTestTar. Typeflag defaults to a
+// regular file and Mode to 0644 for files and 0755 for directories.
+type testEntry struct {
+	Name     string
+	Typeflag byte
+	Body     string
+	Linkname string
+	Mode     int64
+	ModTime  time.Time
+	Format   tar.Format
+	PAX      map[string]string
+}
+
+// writeTestTar writes entries as an uncompressed tarball and returns its path
+func writeTestTar(t testing.TB, entries ...testEntry) string {
+	t.Helper()
+	tarball := filepath.Join(t.TempDir(), "crafted.tar")
+	file, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tw := tar.NewWriter(file)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:       entry.Name,
+			Typeflag:   entry.Typeflag,
+			Linkname:   entry.Linkname,
+			Mode:       entry.Mode,
+			ModTime:    entry.ModTime,
+			Format:     entry.Format,
+			PAXRecords: entry.PAX,
+		}
+		if header.Typeflag == 0 {
+			header.Typeflag = tar.TypeReg
+		}
+		// A global PAX header carries nothing but its records
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			header = &tar.Header{Typeflag: tar.TypeXGlobalHeader, PAXRecords: entry.PAX}
+		} else if header.Mode == 0 {
+			header.Mode = 0o644
+			if header.Typeflag == tar.TypeDir {
+				header.Mode = 0o755
+			}
+		}
+		if header.ModTime.IsZero() && header.Typeflag != tar.TypeXGlobalHeader {
+			header.ModTime = time.Now()
+		}
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.Body))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.Body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return tarball
+}
+
+func TestSafeJoin(t *testing.T) {
+	dest := filepath.Join("models", "dest")
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"manifests/registry.ollama.ai/library/llama2/7b", true},
+		{"blobs/sha256-" + strings.Repeat("a", 64), true},
+		{"manifests/../blobs/x", true},
+		{"./blobs", true},
+		{"..", false},
+		{"../evil", false},
+		{"manifests/../../evil", false},
+		{"blobs/../../../etc/passwd", false},
+		{"manifests/library/../../../dest-sibling/x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := safeJoin(dest, tt.name)
+			if (err == nil) != tt.ok {
+				t.Fatalf("safeJoin(%q) = %q, %v; want ok=%v", tt.name, got, err, tt.ok)
+			}
+			if tt.ok && !strings.HasPrefix(got, dest+string(filepath.Separator)) && got != dest {
+				t.Errorf("safeJoin(%q) = %q, outside %s", tt.name, got, dest)
+			}
+		})
+	}
+}
+
+func TestExtractTarballPathTraversal(t *testing.T) {
+	tests := []struct {
+		name   string
+		entry  string
+		strict bool
+	}{
+		{"parent", "../evil", false},
+		{"through manifests", "manifests/../../evil", false},
+		{"deep", "blobs/../../../evil", false},
+		{"strict", "manifests/../../evil", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The destination is nested so an escaping entry would land in root
+			root := t.TempDir()
+			dest := filepath.Join(root, "a", "models")
+			tarball := writeTestTar(t, testEntry{Name: tt.entry, Body: "pwned"})
+
+			opts := testExtractOptions()
+			opts.Strict = tt.strict
+			err := extractTarball(context.Background(), tarball, dest, opts)
+			if err == nil {
+				t.Fatalf("extracted %s without error", tt.entry)
+			}
+			if !tt.strict && !strings.Contains(err.Error(), "escapes destination") {
+				t.Errorf("got %v, want an escape error", err)
+			}
+			for _, outside := range []string{filepath.Join(root, "evil"), filepath.Join(root, "a", "evil")} {
+				if _, err := os.Stat(outside); !os.IsNotExist(err) {
+					t.Errorf("%s was written outside the destination", tt.entry)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarballPreservesModTimes(t *testing.T) {
+	blob := "blobs/sha256-" + strings.Repeat("a", 64)
+	manifest := "manifests/registry.ollama.ai/library/llama2/7b"
+	dirTime := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tarball := writeTestTar(t,
+		testEntry{Name: "manifests/registry.ollama.ai/library/llama2/", Typeflag: tar.TypeDir, ModTime: dirTime},
+		testEntry{Name: manifest, Body: "{}", ModTime: time.Date(2023, 6, 2, 8, 30, 0, 0, time.UTC)},
+		testEntry{Name: blob, Body: "weights", ModTime: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)},
+	)
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+			dest := t.TempDir()
+			opts := testExtractOptions()
+			opts.Concurrency = concurrency
+			if err := extractTarball(context.Background(), tarball, dest, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				name string
+				want time.Time
+			}{
+				{manifest, time.Date(2023, 6, 2, 8, 30, 0, 0, time.UTC)},
+				{blob, time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)},
+				// Written after the manifest inside it, so restored last
+				{"manifests/registry.ollama.ai/library/llama2", dirTime},
+			}
+			for _, tt := range tests {
+				info, err := os.Stat(filepath.Join(dest, tt.name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !info.ModTime().Equal(tt.want) {
+					t.Errorf("%s modified %s, want %s", tt.name, info.ModTime().UTC(), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarballTruncated(t *testing.T) {
+	src := newTestStore(t)
+	addTestModel(t, src, "llama2:7b", testLayer{modelMediaType, strings.Repeat("GGUF weights ", 10000)})
+	full, err := os.ReadFile(saveTestTarball(t, src, compressNone, "llama2:7b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut off inside a header, inside the weights, and before the end blocks
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"in header", 700},
+		{"in blob", len(full) / 2},
+		{"before end", len(full) - 1024 - 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tarball := filepath.Join(t.TempDir(), "truncated.tar")
+			if err := os.WriteFile(tarball, full[:tt.size], 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for _, concurrency := range []int{1, 4} {
+				dest := newTestStore(t)
+				opts := testExtractOptions()
+				opts.Concurrency = concurrency
+				if err := extractTarball(context.Background(), tarball, dest, opts); err == nil {
+					t.Fatalf("concurrency %d: extracted a truncated tarball without error", concurrency)
+				}
+
+				// Files that were cut off are neither kept nor left as .partial
+				for name, data := range readStoreFiles(t, dest) {
+					if strings.HasSuffix(name, partialSuffix) {
+						t.Errorf("concurrency %d: left %s behind", concurrency, name)
+					} else if want := readStoreFiles(t, src)[name]; data != want {
+						t.Errorf("concurrency %d: %s has %d bytes, want %d", concurrency, name, len(data), len(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+// testBlobEntry returns the blobs/ entry name data is stored under
+func testBlobEntry(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "blobs/sha256-" + hex.EncodeToString(sum[:])
+}
+
+// BenchmarkExtractConcurrency extracts and verifies a tarball of several
+// large blobs inline and with a writer pool
+func BenchmarkExtractConcurrency(b *testing.B) {
+	const blobs, blobSize = 8, 16 << 20
+	entries := []testEntry{}
+	for i := range blobs {
+		data := benchData(blobSize)
+		data[0] = byte(i) // Distinct digests
+		entries = append(entries, testEntry{Name: testBlobEntry(string(data)), Body: string(data)})
+	}
+	tarball := writeTestTar(b, entries...)
+
+	for _, concurrency := range []int{1, max(runtime.GOMAXPROCS(0), 4)} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			opts := testExtractOptions()
+			opts.Concurrency = concurrency
+			opts.Verify = true
+			b.SetBytes(blobs * blobSize)
+			for b.Loop() {
+				if err := extractTarball(context.Background(), tarball, b.TempDir(), opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarballDuplicateEntries(t *testing.T) {
+	blob := testBlobEntry("weights")
+	manifest := "manifests/registry.ollama.ai/library/llama2/7b"
+	tests := []struct {
+		name    string
+		entries []testEntry
+		verify  bool
+		wantErr bool
+		want    map[string]string
+	}{
+		{
+			name:    "manifest",
+			entries: []testEntry{{Name: manifest, Body: "first"}, {Name: manifest, Body: "second"}},
+			want:    map[string]string{manifest: "first"},
+		},
+		{
+			name:    "manifest spelled differently",
+			entries: []testEntry{{Name: manifest, Body: "first"}, {Name: "./manifests//registry.ollama.ai/library/llama2/7b", Body: "second"}},
+			want:    map[string]string{manifest: "first"},
+		},
+		{
+			name:    "identical blob",
+			entries: []testEntry{{Name: blob, Body: "weights"}, {Name: blob, Body: "weights"}},
+			verify:  true,
+			want:    map[string]string{blob: "weights"},
+		},
+		{
+			name:    "conflicting blob",
+			entries: []testEntry{{Name: blob, Body: "weights"}, {Name: blob, Body: "tampered"}},
+			verify:  true,
+			wantErr: true,
+		},
+		{
+			name:    "conflicting blob without verify",
+			entries: []testEntry{{Name: blob, Body: "weights"}, {Name: blob, Body: "tampered"}},
+			want:    map[string]string{blob: "weights"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := newTestStore(t)
+			opts := testExtractOptions()
+			opts.Verify = tt.verify
+			err := extractTarball(context.Background(), writeTestTar(t, tt.entries...), dest, opts)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "conflicting content") {
+					t.Errorf("got %v, want a conflicting content error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := readStoreFiles(t, dest)
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %q, want the first copy %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarballEntryTypes(t *testing.T) {
+	// Long enough to need a PAX or GNU long-name record
+	longManifest := "manifests/registry.ollama.ai/library/" + strings.Repeat("m", 80) + "/" + strings.Repeat("t", 60)
+	blob := testBlobEntry("weights")
+	tests := []struct {
+		name    string
+		entries []testEntry
+		wantErr string
+	}{
+		{"ustar", []testEntry{{Name: blob, Body: "weights", Format: tar.FormatUSTAR}}, ""},
+		{"pax long name", []testEntry{{Name: longManifest, Body: "{}", Format: tar.FormatPAX}}, ""},
+		{"pax records", []testEntry{{Name: blob, Body: "weights", Format: tar.FormatPAX, PAX: map[string]string{"comment": "saved by ollie"}}}, ""},
+		{"gnu long name", []testEntry{{Name: longManifest, Body: "{}", Format: tar.FormatGNU}}, ""},
+		{"global pax header", []testEntry{
+			{Name: "pax_global_header", Typeflag: tar.TypeXGlobalHeader, PAX: map[string]string{"comment": "git archive"}},
+			{Name: blob, Body: "weights"},
+		}, ""},
+		{"directories", []testEntry{{Name: "manifests/", Typeflag: tar.TypeDir}, {Name: "blobs/", Typeflag: tar.TypeDir}, {Name: blob, Body: "weights"}}, ""},
+		{"symlink", []testEntry{{Name: blob, Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}}, "unsupported symlink entry"},
+		{"hard link", []testEntry{{Name: blob, Typeflag: tar.TypeLink, Linkname: "blobs/other"}}, "unsupported hard link entry"},
+		{"character device", []testEntry{{Name: blob, Typeflag: tar.TypeChar}}, "unsupported character device entry"},
+		{"fifo", []testEntry{{Name: blob, Typeflag: tar.TypeFifo}}, "unsupported FIFO entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := newTestStore(t)
+			err := extractTarball(context.Background(), writeTestTar(t, tt.entries...), dest, testExtractOptions())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				if info, err := os.Lstat(filepath.Join(dest, blob)); err == nil {
+					t.Errorf("%s was extracted as %s", blob, info.Mode().Type())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := readStoreFiles(t, dest)
+			for _, entry := range tt.entries {
+				if entry.Typeflag == 0 && got[entry.Name] != entry.Body {
+					t.Errorf("%s = %q, want %q", entry.Name, got[entry.Name], entry.Body)
+				}
+			}
+			if _, ok := got["pax_global_header"]; ok {
+				t.Error("extracted the global PAX header as a file")
+			}
+		})
+	}
+}
+
+func TestExtractTarballAbsolutePaths(t *testing.T) {
+	tests := []string{
+		"/etc/passwd",
+		"/manifests/registry.ollama.ai/library/llama2/7b",
+		"//server/share/evil",
+		`\evil`,
+		`C:\Windows\evil`,
+		"C:/evil",
+		"c:evil",
+	}
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := safeJoin(t.TempDir(), name); err == nil || !strings.Contains(err.Error(), "is absolute") {
+				t.Errorf("safeJoin(%q) = %v, want an absolute path error", name, err)
+			}
+
+			// Rejected before anything is written, even without --strict checks
+			dest := t.TempDir()
+			opts := testExtractOptions()
+			opts.Strict = false
+			err := extractTarball(context.Background(), writeTestTar(t, testEntry{Name: name, Body: "pwned"}), dest, opts)
+			if err == nil || !strings.Contains(err.Error(), "is absolute") {
+				t.Errorf("got %v, want an absolute path error", err)
+			}
+		})
+	}
+}
+
+func TestResolveLoadOwner(t *testing.T) {
+	tests := []struct {
+		name         string
+		owner        string
+		numericOwner string
+		uid, gid     int
+		uidSet       bool
+		gidSet       bool
+		wantUID      int
+		wantGID      int
+		wantErr      bool
+	}{
+		{name: "owner none", owner: "none", wantUID: -1, wantGID: -1},
+		{name: "numeric owner spec", owner: "5:6", wantUID: 5, wantGID: 6},
+		{name: "uid over owner", owner: "5:6", uid: 7, uidSet: true, wantUID: 7, wantGID: 6},
+		{name: "gid over owner", owner: "5:6", gid: 8, gidSet: true, wantUID: 5, wantGID: 8},
+		{name: "uid and gid skip the lookup", owner: "no-such-user-ollie:no-such-group-ollie", uid: 7, gid: 8, uidSet: true, gidSet: true, wantUID: 7, wantGID: 8},
+		{name: "uid and gid without owner", uid: 0, gid: 0, uidSet: true, gidSet: true, wantUID: 0, wantGID: 0},
+		{name: "uid -1 keeps the owner's gid", owner: "5:6", uid: -1, uidSet: true, wantUID: -1, wantGID: 6},
+		{name: "numeric owner over owner", owner: "5:6", numericOwner: "9:10", wantUID: 9, wantGID: 10},
+		{name: "numeric owner over uid", numericOwner: "9:10", uid: 7, uidSet: true, wantUID: 9, wantGID: 10},
+		{name: "unknown owner", owner: "no-such-user-ollie:no-such-group-ollie", wantErr: true},
+		{name: "unknown owner with only uid", owner: "no-such-user-ollie:no-such-group-ollie", uid: 7, uidSet: true, wantErr: true},
+		{name: "invalid numeric owner", numericOwner: "ollama:ollama", wantErr: true},
+		{name: "negative uid", uid: -2, gid: 0, uidSet: true, gidSet: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, gid, err := resolveLoadOwner(tt.owner, tt.numericOwner, tt.uid, tt.gid, tt.uidSet, tt.gidSet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLoadOwner = %d, %d, %v; wantErr %v", uid, gid, err, tt.wantErr)
+			}
+			if !tt.wantErr && (uid != tt.wantUID || gid != tt.wantGID) {
+				t.Errorf("resolveLoadOwner = %d, %d; want %d, %d", uid, gid, tt.wantUID, tt.wantGID)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestExtractTarballDirectoryModes(t *testing.T) {
+	// Directory modes are only predictable under a known umask
+	defer syscall.Umask(syscall.Umask(0o022))
+
+	src := newTestStore(t)
+	addDefaultTestModel(t, src, "llama2:7b")
+	tarball := saveTestTarball(t, src, compressNone, "llama2:7b")
+
+	tests := []struct {
+		name string
+		dest func(t *testing.T) string
+	}{
+		{"missing destination", func(t *testing.T) string { return filepath.Join(t.TempDir(), "models") }},
+		{"empty destination", func(t *testing.T) string { return t.TempDir() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := tt.dest(t)
+			if err := extractTarball(context.Background(), tarball, dest, testExtractOptions()); err != nil {
+				t.Fatal(err)
+			}
+
+			dirs := []string{
+				"manifests",
+				"blobs",
+				"manifests/registry.ollama.ai",
+				"manifests/registry.ollama.ai/library",
+				"manifests/registry.ollama.ai/library/llama2",
+			}
+			for _, dir := range dirs {
+				info, err := os.Stat(filepath.Join(dest, dir))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := info.Mode().Perm(); got != extractDirMode {
+					t.Errorf("%s has mode %#o, want %#o", dir, got, extractDirMode)
+				}
+			}
+		})
+	}
+}
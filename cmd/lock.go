@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// lockFileName is the advisory lock file commands that modify the models
+// directory hold while they run
+const lockFileName = ".ollie.lock"
+
+// lockPollInterval is how often a held lock is retried
+const lockPollInterval = 100 * time.Millisecond
+
+var (
+	noLock      bool
+	lockTimeout time.Duration
+)
+
+// lockModelsDir takes an exclusive advisory lock on the models directory so
+// that two ollie processes don't interleave writes to the same blobs and
+// manifests, waiting up to --lock-timeout for another process to release it.
+// The returned function releases the lock. With --no-lock it does nothing.
+func lockModelsDir(modelPath string) (func(), error) {
+	if noLock {
+		return func() {}, nil
+	}
+
+	lockPath := filepath.Join(modelPath, lockFileName)
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	waiting := false
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("models directory is locked by another ollie process (timed out after %s; see %s or use --no-lock)", lockTimeout, lockPath)
+		}
+		if !waiting {
+			slog.Info("Waiting for another ollie process to release the models directory", "lock", lockPath)
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	// Closing the file releases the lock
+	return func() { file.Close() }, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{loadCmd, deleteCmd, gcCmd, copyCmd, renameCmd, tagCmd, importCmd, verifyCmd, pruneCmd} {
+		cmd.Flags().BoolVar(&noLock, "no-lock", false, "don't lock the models directory against other ollie processes")
+		cmd.Flags().DurationVar(&lockTimeout, "lock-timeout", 30*time.Second, "how long to wait for another ollie process to release the models directory")
+	}
+}
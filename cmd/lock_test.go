@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestLockHelperProcess isn't a real test: TestLockModelsDirSerializes runs
+// the test binary again to hold the lock from a second process. It takes the
+// lock, reports it on stdout, and holds it until stdin is closed.
+func TestLockHelperProcess(t *testing.T) {
+	modelPath := os.Getenv("OLLIE_TEST_LOCK_HELPER")
+	if modelPath == "" {
+		t.Skip("helper process for TestLockModelsDirSerializes")
+	}
+	noLock, lockTimeout = false, 10*time.Second
+	unlock, err := lockModelsDir(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout.WriteString("locked\n")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	unlock()
+}
+
+func TestLockModelsDirSerializes(t *testing.T) {
+	savedNoLock, savedTimeout := noLock, lockTimeout
+	t.Cleanup(func() { noLock, lockTimeout = savedNoLock, savedTimeout })
+	noLock = false
+	modelPath := newTestStore(t)
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	helper.Env = append(os.Environ(), "OLLIE_TEST_LOCK_HELPER="+modelPath)
+	stdin, err := helper.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		stdin.Close()
+		helper.Wait()
+	})
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("helper process didn't take the lock: %q, %v", line, err)
+	}
+
+	// The other process holds the lock, so this one times out
+	lockTimeout = 300 * time.Millisecond
+	if unlock, err := lockModelsDir(modelPath); err == nil {
+		unlock()
+		t.Fatal("took the lock while another process held it")
+	}
+
+	// Once the other process releases it, a waiting lock goes through
+	lockTimeout = 10 * time.Second
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		stdin.Close()
+	}()
+	start := time.Now()
+	unlock, err := lockModelsDir(modelPath)
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+	unlock()
+	if waited := time.Since(start); waited < 100*time.Millisecond {
+		t.Errorf("took the lock after %s, before the other process released it", waited)
+	}
+
+	// With --no-lock nothing waits
+	lockTimeout = 0
+	noLock = true
+	unlock, err = lockModelsDir(modelPath)
+	if err != nil {
+		t.Fatalf("--no-lock: %v", err)
+	}
+	unlock()
+}
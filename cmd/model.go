@@ -0,0 +1,69 @@
+package cmd
+
+import "ollie/pkg/ollama"
+
+// The model name and manifest logic lives in pkg/ollama so other tools can
+// import it. These aliases and wrappers let the commands use it unqualified.
+type (
+	ModelName = ollama.ModelName
+	Layer     = ollama.Layer
+	Manifest  = ollama.Manifest
+)
+
+// manifestSchemaVersion is the only manifest schema version Ollama writes
+const manifestSchemaVersion = ollama.ManifestSchemaVersion
+
+// Media types Ollama uses for manifests, configs, and layers
+const (
+	manifestMediaType = ollama.ManifestMediaType
+	configMediaType   = ollama.ConfigMediaType
+	modelMediaType    = ollama.ModelMediaType
+	templateMediaType = ollama.TemplateMediaType
+	systemMediaType   = ollama.SystemMediaType
+	paramsMediaType   = ollama.ParamsMediaType
+	licenseMediaType  = ollama.LicenseMediaType
+)
+
+func parseModelName(name string) (*ModelName, error) {
+	return ollama.ParseModelName(name)
+}
+
+func readManifest(path string) (*Manifest, error) {
+	return ollama.ReadManifest(path)
+}
+
+func blobFileName(digest string) string {
+	return ollama.BlobFileName(digest)
+}
+
+func parseManifest(path string) ([]Layer, error) {
+	return ollama.ParseManifest(path)
+}
+
+func manifestRelPath(modelName *ModelName) string {
+	return ollama.ManifestRelPath(modelName)
+}
+
+func modelFromManifestPath(path string) (string, *ModelName, error) {
+	return ollama.ModelFromManifestPath(path)
+}
+
+func getFilePaths(modelName *ModelName, modelPath string, keep func(Layer) bool) ([]string, error) {
+	return ollama.FilePaths(modelName, modelPath, keep)
+}
+
+func selectVariant(index *Manifest, variant string) (*ollama.IndexEntry, error) {
+	return ollama.SelectVariant(index, variant)
+}
+
+func readVariantManifest(modelPath string, entry ollama.IndexEntry) (*Manifest, error) {
+	return ollama.ReadVariantManifest(modelPath, entry)
+}
+
+func readConfigBlob(modelPath string, config Layer) ([]byte, *ollama.ModelConfig, error) {
+	return ollama.ReadConfigBlob(modelPath, config)
+}
+
+func listModels(modelPath string) ([]*ModelName, error) {
+	return ollama.ListModels(modelPath)
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openForArchive opens a file for reading. With noatime, it's opened with
+// O_NOATIME so archiving doesn't update its access time; the kernel only allows
+// that for files the caller owns, so it falls back to a plain open otherwise.
+func openForArchive(path string, noatime bool) (*os.File, error) {
+	if noatime {
+		file, err := os.OpenFile(path, os.O_RDONLY|unix.O_NOATIME, 0)
+		if !errors.Is(err, unix.EPERM) {
+			return file, err
+		}
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package cmd
+
+import "os"
+
+// openForArchive opens a file for reading; noatime is only supported on Linux
+func openForArchive(path string, noatime bool) (*os.File, error) {
+	return os.Open(path)
+}
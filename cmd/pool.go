@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// writerPool finishes extracted tar entries on a bounded number of goroutines.
+// The tar stream can only be read sequentially, so the caller spools each
+// entry's content to its .partial file and moves on to the next entry, while a
+// worker verifies the spooled file, applies its metadata, and renames it into
+// place. Large blobs are then hashed in parallel instead of one after another.
+type writerPool struct {
+	slots    chan struct{}
+	wg       sync.WaitGroup
+	uid, gid int
+	mode     os.FileMode
+
+	mu  sync.Mutex
+	err error
+}
+
+// newWriterPool returns a pool finishing at most n files at once, owned by uid
+// and gid and, when mode is non-zero, with exactly that mode
+func newWriterPool(n, uid, gid int, mode os.FileMode) *writerPool {
+	return &writerPool{slots: make(chan struct{}, n), uid: uid, gid: gid, mode: mode}
+}
+
+// Submit spools the current entry's content from r to targetPath's .partial
+// file and hands it to a worker to finish. It blocks while all workers are
+// busy and returns the first error reported by any worker so far.
+func (p *writerPool) Submit(r io.Reader, targetPath string, header *tar.Header, verify bool) error {
+	if err := p.firstErr(); err != nil {
+		return err
+	}
+
+	partialPath := targetPath + partialSuffix
+	if _, err := spoolEntry(r, partialPath, targetPath, header, false); err != nil {
+		p.setErr(err)
+		return err
+	}
+
+	p.slots <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		actual := ""
+		if verify {
+			var err error
+			if actual, err = fileSHA256(partialPath); err != nil {
+				os.Remove(partialPath)
+				p.setErr(fmt.Errorf("failed to hash %s: %w", targetPath, err))
+				return
+			}
+		}
+		if err := finishEntry(partialPath, targetPath, header, verify, actual, p.uid, p.gid, p.mode); err != nil {
+			p.setErr(err)
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until every submitted entry is written and returns the first error
+func (p *writerPool) Wait() error {
+	p.wg.Wait()
+	return p.firstErr()
+}
+
+func (p *writerPool) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *writerPool) firstErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
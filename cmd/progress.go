@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// progressInterval limits how often the progress line is redrawn
+const progressInterval = 100 * time.Millisecond
+
+// Progress output formats selected with --progress
+const (
+	progressFormatBar  = "bar"
+	progressFormatJSON = "json"
+)
+
+// progressEvent is one line of --progress=json output
+type progressEvent struct {
+	Event string `json:"event"`
+	Name  string `json:"name,omitempty"`
+	Bytes int64  `json:"bytes"`
+	Total int64  `json:"total"`
+}
+
+// progressBar reports byte progress of a long-running operation on stderr,
+// either as a redrawn line or, in JSON mode, as one event per file.
+// A nil *progressBar is valid and reports nothing. It's safe for concurrent
+// use, since progress may be reported from a goroutine copying into an
+// external decompressor while the caller reports files.
+type progressBar struct {
+	mu        sync.Mutex
+	out       io.Writer
+	label     string
+	total     int64
+	current   int64
+	lastDrawn time.Time
+	finished  bool
+	json      bool
+}
+
+// newProgressBar returns a progress bar for total bytes, or nil when quiet is set
+// or stderr is not a terminal. With --progress=json, events are always written,
+// since a program asked for them.
+func newProgressBar(label string, total int64, quiet bool) *progressBar {
+	if progressFormat == progressFormatJSON {
+		return &progressBar{out: os.Stderr, label: label, total: total, json: true}
+	}
+	if quiet || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progressBar{out: os.Stderr, label: label, total: total}
+}
+
+// Write records len(p) bytes of progress, so the bar can be used with io.TeeReader
+// or io.MultiWriter.
+func (p *progressBar) Write(b []byte) (int, error) {
+	p.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Add records n bytes of progress and redraws the bar if enough time has passed
+func (p *progressBar) Add(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current += n
+	if !p.json && time.Since(p.lastDrawn) >= progressInterval {
+		p.draw()
+	}
+}
+
+// Finish draws the final state of the bar and ends the line.
+// Calling Finish more than once has no further effect.
+func (p *progressBar) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	if p.json {
+		p.emit(progressEvent{Event: "done"})
+		return
+	}
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+// File reports that a file has been processed. Only JSON mode shows it.
+func (p *progressBar) File(name string) {
+	if p == nil || !p.json {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.emit(progressEvent{Event: "file", Name: name})
+}
+
+// emit writes event as a JSON line with the current byte counts. The caller
+// holds p.mu.
+func (p *progressBar) emit(event progressEvent) {
+	event.Bytes, event.Total = p.current, p.total
+	data, _ := json.Marshal(event)
+	fmt.Fprintln(p.out, string(data))
+}
+
+// draw redraws the progress line. The caller holds p.mu.
+func (p *progressBar) draw() {
+	p.lastDrawn = time.Now()
+	if p.total > 0 {
+		percent := float64(p.current) / float64(p.total) * 100
+		fmt.Fprintf(p.out, "\r%s %5.1f%% (%s / %s)", p.label, percent, formatBytes(p.current), formatBytes(p.total))
+		return
+	}
+	fmt.Fprintf(p.out, "\r%s %s", p.label, formatBytes(p.current))
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestProgressBarConcurrent reports progress from one goroutine, as the zstd
+// decoder does while reading its input, while another reports files. Run it
+// with -race to catch unsynchronized access.
+func TestProgressBarConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	bar := &progressBar{out: &out, label: "Loading", total: 1000, json: true}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			bar.Write([]byte{0})
+		}
+	}()
+	for i := range 100 {
+		bar.File(strings.Repeat("x", i%5+1))
+	}
+	wg.Wait()
+	bar.Finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var last progressEvent
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("failed to decode last event %q: %v", lines[len(lines)-1], err)
+	}
+	if last.Event != "done" || last.Bytes != 1000 {
+		t.Errorf("last event = %+v, want done with 1000 bytes", last)
+	}
+	if len(lines) != 101 {
+		t.Errorf("got %d events, want 100 file events and done", len(lines))
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1 << 20, "1.0 MiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// parseAge parses an age such as 30d, 2w, or 12h. Days (d) and weeks (w) are
+// accepted on top of the units time.ParseDuration understands.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (expected e.g. 30d, 2w, or 12h)", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (expected e.g. 30d, 2w, or 12h)", s)
+	}
+	return age, nil
+}
+
+// staleModel is a model whose manifest hasn't changed since before the cutoff
+type staleModel struct {
+	name    *ModelName
+	modTime time.Time
+}
+
+// staleModels returns the models, other than those .ollieignore matches, whose
+// manifest was last modified before cutoff. A manifest's mtime is when the
+// model was last pulled or loaded.
+func staleModels(modelPath string, cutoff time.Time) ([]staleModel, error) {
+	models, err := listBulkModels(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []staleModel{}
+	for _, model := range models {
+		info, err := os.Stat(filepath.Join(modelPath, manifestRelPath(model)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat manifest of %s: %w", model.ShortString(), err)
+		}
+		if info.ModTime().Before(cutoff) {
+			stale = append(stale, staleModel{name: model, modTime: info.ModTime()})
+		}
+	}
+	return stale, nil
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune --older-than AGE",
+	Short: "Remove models that haven't been pulled or loaded recently",
+	Long: `Remove models whose manifest hasn't been modified within AGE, then remove
+the blobs no remaining model references, as "ollie gc" does.
+
+A manifest's modification time is when the model was last pulled, so it
+stands in for when the model was last updated. "ollie load" keeps the
+modification times recorded in the tarball, so a model loaded from an old
+tarball counts as old right away and is pruned on the next run; run
+"touch" on its manifest after loading to keep it. AGE is a number of days
+(30d), weeks (2w), or any Go duration such as 12h. Models matched by
+.ollieignore are never pruned (see "ollie list --help").
+
+Use --dry-run to list what would be removed first.
+
+Examples:
+  ollie prune --older-than 30d --dry-run
+  ollie prune --older-than 2w`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		age, err := parseAge(pruneOlderThan)
+		if err != nil {
+			return err
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		stale, err := staleModels(modelPath, time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+		except := []*ModelName{}
+		for _, model := range stale {
+			except = append(except, model.name)
+		}
+
+		// Blobs still referenced once the stale models are gone are kept
+		refs, err := referencedBlobs(modelPath, except...)
+		if err != nil {
+			return err
+		}
+		orphans, err := orphanedBlobs(modelPath, refs)
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, blob := range orphans {
+			total += blob.Size()
+		}
+
+		if pruneDryRun {
+			for _, model := range stale {
+				fmt.Fprintf(os.Stderr, "Would delete %s (last modified %s)\n", model.name.ShortString(), model.modTime.Format(time.DateOnly))
+			}
+			for _, blob := range orphans {
+				fmt.Fprintf(os.Stderr, "Would delete blob %s (%s)\n", blob.Name(), formatBytes(blob.Size()))
+			}
+			fmt.Fprintf(os.Stderr, "Would free %s (%d model(s), %d blob(s))\n", formatBytes(total), len(stale), len(orphans))
+			return nil
+		}
+
+		for _, model := range stale {
+			manifestPath := filepath.Join(modelPath, manifestRelPath(model.name))
+			if err := os.Remove(manifestPath); err != nil {
+				return fmt.Errorf("failed to delete manifest of %s: %w", model.name.ShortString(), err)
+			}
+			removeEmptyParents(filepath.Dir(manifestPath), filepath.Join(modelPath, "manifests"))
+			slog.Info("Deleted model", "model", model.name.ShortString(), "modified", model.modTime.Format(time.DateOnly))
+		}
+		for _, blob := range orphans {
+			if err := os.Remove(filepath.Join(modelPath, "blobs", blob.Name())); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete blob %s: %w", blob.Name(), err)
+			}
+		}
+
+		slog.Info("Pruned models", "models", len(stale), "blobs", len(orphans), "size", formatBytes(total))
+		return nil
+	},
+}
+
+var (
+	pruneOlderThan string
+	pruneDryRun    bool
+)
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "remove models whose manifest is older than AGE, e.g. 30d, 2w, or 12h")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "report what would be deleted without removing anything")
+	pruneCmd.MarkFlagRequired("older-than")
+	rootCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:     "rename SOURCE DESTINATION",
+	Aliases: []string{"move", "mv"},
+	Short:   "Rename a model",
+	Long: `Rename an Ollama model by moving its manifest to a new name.
+Blobs are content-addressed and shared, so they are left untouched.
+
+Examples:
+  ollie rename llama2:latest llama2:old
+  ollie rename llama2 myorg/llama2:v1 --force`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Parse model names
+		source, err := parseModelName(args[0])
+		if err != nil {
+			return err
+		}
+		dest, err := parseModelName(args[1])
+		if err != nil {
+			return err
+		}
+		if source.String() == dest.String() {
+			return fmt.Errorf("source and destination are the same model: %s", source.ShortString())
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		sourcePath, err := copyModelManifest(modelPath, source, dest, renameForce)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(sourcePath); err != nil {
+			return fmt.Errorf("failed to remove old manifest: %w", err)
+		}
+		removeEmptyParents(filepath.Dir(sourcePath), filepath.Join(modelPath, "manifests"))
+
+		slog.Info("Renamed model", "source", source.ShortString(), "dest", dest.ShortString())
+		return nil
+	},
+}
+
+var renameForce bool
+
+func init() {
+	renameCmd.Flags().BoolVarP(&renameForce, "force", "f", false, "overwrite the destination if it already exists")
+	rootCmd.AddCommand(renameCmd)
+}
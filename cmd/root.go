@@ -2,12 +2,34 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
-var version = "0.1.0"
+// Build metadata, set at build time with
+// -ldflags "-X ollie/cmd.version=... -X ollie/cmd.commit=... -X ollie/cmd.date=..."
+var (
+	version = "0.1.0"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+var (
+	// quiet suppresses progress output on stderr
+	quiet bool
+	// verbose logs each file at info level as it is archived or extracted
+	verbose bool
+	// progressFormat selects a progress bar or JSON Lines progress events
+	progressFormat string
+	// modelsPathFlag overrides the Ollama models directory when set
+	modelsPathFlag string
+	// jsonErrors prints errors as JSON objects for scripts
+	jsonErrors bool
+	// logLevel is the minimum level of diagnostic messages logged to stderr
+	logLevel string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -15,19 +37,53 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI helper toolset for Ollama",
 	Long: `Ollie is a command-line interface tool that provides utility functions
 for working with Ollama. It offers various commands to make your Ollama
-experience more convenient and efficient.`,
+experience more convenient and efficient.
+
+The models directory is taken from, in order of precedence, --models-path,
+OLLIE_MODELS, or OLLAMA_MODELS. OLLIE_MODELS points ollie at another store,
+such as a staging directory, without changing the server's OLLAMA_MODELS.
+When none is set, the first of these that exists is used:
+  /usr/share/ollama/.ollama/models  (Linux system install)
+  ~/.ollama/models
+  $XDG_DATA_HOME/ollama/models      (~/.local/share/ollama/models if unset)
+  /root/.ollama/models              (Docker image)
+falling back to ~/.ollama/models.`,
 	Version:       version,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if progressFormat != progressFormatBar && progressFormat != progressFormatJSON {
+			return fmt.Errorf("invalid --progress %q (expected bar or json)", progressFormat)
+		}
+
+		// Status messages and warnings go through slog, so the level decides
+		// how much ollie reports on stderr
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+			return fmt.Errorf("invalid --log-level %q (expected error, warn, info, or debug)", logLevel)
+		}
+		slog.SetLogLoggerLevel(level)
+		return nil
+	},
 	CompletionOptions: cobra.CompletionOptions{
-		DisableDefaultCmd: true,
+		HiddenDefaultCmd: true,
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log each file as it is processed (replaces the progress bar)")
+	rootCmd.PersistentFlags().StringVar(&progressFormat, "progress", progressFormatBar, `progress output on stderr: bar, or json for one JSON event per line ({"event": "file"|"done", ...})`)
+	rootCmd.PersistentFlags().StringVar(&modelsPathFlag, "models-path", "", "Ollama models directory (overrides OLLIE_MODELS and OLLAMA_MODELS)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "minimum level of messages logged to stderr: error, warn, info, or debug")
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, `print errors as JSON ({"error": ..., "code": ...})`)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Missing models exit with status 2, corrupt manifests with status 3, and
+// interrupted saves and loads with status 130.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(reportError(err))
 	}
 }
@@ -2,155 +2,634 @@ package cmd
 
 import (
 	"archive/tar"
-	"encoding/json"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
-// ModelName represents the parsed components of an Ollama model name
-type ModelName struct {
-	Host      string
-	Namespace string
-	Model     string
-	Tag       string
-}
-
-// Manifest represents the structure of an Ollama manifest file
-type Manifest struct {
-	Config struct {
-		Digest string `json:"digest"`
-	} `json:"config"`
-	Layers []struct {
-		Digest string `json:"digest"`
-	} `json:"layers"`
-}
-
-// parseModelName parses an Ollama model name into its components
-// Supports formats:
-//   - host/namespace/model:tag
-//   - namespace/model:tag
-//   - namespace/model
-//   - model:tag
-//   - model
-func parseModelName(name string) (*ModelName, error) {
-	patterns := []string{
-		`^(?P<host>[^/]+)/(?P<namespace>[^/]+)/(?P<model>[^:]+):(?P<tag>.+)$`, // host/namespace/model:tag
-		`^(?P<namespace>[^/]+)/(?P<model>[^:]+):(?P<tag>.+)$`,                 // namespace/model:tag
-		`^(?P<namespace>[^/]+)/(?P<model>[^:]+)$`,                             // namespace/model
-		`^(?P<model>[^:]+):(?P<tag>.+)$`,                                      // model:tag
-		`^(?P<model>[^:]+)$`,                                                  // model
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if matches := re.FindStringSubmatch(name); matches != nil {
-			result := &ModelName{
-				Host:      "registry.ollama.ai",
-				Namespace: "library",
-				Model:     "",
-				Tag:       "latest",
-			}
-
-			// Extract named groups
-			names := re.SubexpNames()
-			for i, match := range matches {
-				switch names[i] {
-				case "host":
-					result.Host = match
-				case "namespace":
-					result.Namespace = match
-				case "model":
-					result.Model = match
-				case "tag":
-					result.Tag = match
+// completeModelNames provides shell completion for locally available model names
+func completeModelNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	modelPath, err := getOllamaModelsPath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	models, err := listModels(modelPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := []string{}
+	for _, model := range models {
+		if name := model.ShortString(); strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFirstModelName completes model names for the first argument only
+func completeFirstModelName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeModelNames(cmd, args, toComplete)
+}
+
+// hasExplicitTag reports whether a model name string includes a :tag
+func hasExplicitTag(name string) bool {
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.LastIndex(name, ":") > strings.LastIndex(name, "/")
+}
+
+// listTags returns the tags available locally for a model, in sorted order
+func listTags(modelPath string, modelName *ModelName) ([]string, error) {
+	modelDir := filepath.Dir(filepath.Join(modelPath, manifestRelPath(modelName)))
+	entries, err := os.ReadDir(modelDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrModelNotFound, modelName.Namespace, modelName.Model)
+		}
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	tags := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			tags = append(tags, entry.Name())
+		}
+	}
+	return tags, nil
+}
+
+// resolveModelNames expands a model name string into the local models it refers to.
+// A pinned @sha256 digest selects the tag whose manifest has that digest. Without an
+// explicit tag, latest is used if present, then a single available tag; otherwise all
+// tags are returned when allTags is set, or an error lists the available tags.
+func resolveModelNames(name, modelPath string, allTags bool) ([]*ModelName, error) {
+	modelName, err := parseModelName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelName.Digest == "" && hasExplicitTag(name) {
+		return []*ModelName{modelName}, nil
+	}
+
+	tags, err := listTags(modelPath, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	withTag := func(tag string) *ModelName {
+		resolved := *modelName
+		resolved.Tag = tag
+		resolved.Digest = ""
+		return &resolved
+	}
+
+	// Find the manifest matching a pinned digest. Several tags may share the
+	// same manifest, which only counts as ambiguous if the digests differ.
+	if modelName.Digest != "" {
+		want := strings.TrimPrefix(modelName.Digest, "sha256:")
+		var match *ModelName
+		matchDigest := ""
+		for _, tag := range tags {
+			if hasExplicitTag(name) && tag != modelName.Tag {
+				continue
+			}
+			candidate := withTag(tag)
+			digest, err := fileSHA256(filepath.Join(modelPath, manifestRelPath(candidate)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash manifest: %w", err)
+			}
+			if !strings.HasPrefix(digest, want) {
+				continue
+			}
+			if match != nil && digest != matchDigest {
+				return nil, fmt.Errorf("digest %s is ambiguous for %s", modelName.Digest, name)
+			}
+			if match == nil || tag == modelName.Tag {
+				match, matchDigest = candidate, digest
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("no manifest of %s matches digest %s", name, modelName.Digest)
+		}
+		return []*ModelName{match}, nil
+	}
+
+	if allTags {
+		resolved := []*ModelName{}
+		for _, tag := range tags {
+			resolved = append(resolved, withTag(tag))
+		}
+		return resolved, nil
+	}
+
+	if slices.Contains(tags, modelName.Tag) {
+		return []*ModelName{modelName}, nil
+	}
+	if len(tags) == 1 {
+		return []*ModelName{withTag(tags[0])}, nil
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%w: %s has no tag manifests", ErrModelNotFound, name)
+	}
+	return nil, fmt.Errorf("model %s has no %s tag and multiple tags, specify one or use --all-tags: %s", name, modelName.Tag, strings.Join(tags, ", "))
+}
+
+// collectFilePaths resolves the relative paths for several models, merging them
+// into a single list without duplicating blobs shared between models. The keep
+// filter is passed through to getFilePaths, and variant selects the variant of
+// models whose manifest is an index. The resolved models are returned too.
+func collectFilePaths(modelNames []string, modelPath string, allTags bool, variant string, keep func(Layer) bool) ([]string, []*ModelName, error) {
+	seen := map[string]bool{}
+	paths := []string{}
+	models := []*ModelName{}
+
+	for _, modelNameStr := range modelNames {
+		resolved, err := resolveModelNames(modelNameStr, modelPath, allTags)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, modelName := range resolved {
+			modelName.Variant = variant
+			modelPaths, err := getFilePaths(modelName, modelPath, keep)
+			if err != nil {
+				return nil, nil, err
+			}
+			models = append(models, modelName)
+
+			for _, relPath := range modelPaths {
+				if seen[relPath] {
+					continue
 				}
+				seen[relPath] = true
+				paths = append(paths, relPath)
 			}
+		}
+	}
 
-			return result, nil
+	return paths, models, nil
+}
+
+// isModelGlob reports whether a model argument is a glob pattern
+func isModelGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// expandModelGlobs replaces each glob pattern among names with the fully
+// qualified names of the local models it matches, keeping other names as they
+// are. A pattern is matched against each model's name with and without its
+// namespace and host, both with and without the tag, so "llama*",
+// "library/llama*", and "myorg/*:latest" all work as expected.
+func expandModelGlobs(modelPath string, names []string) ([]string, error) {
+	if !slices.ContainsFunc(names, isModelGlob) {
+		return names, nil
+	}
+	models, err := listModels(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if !isModelGlob(name) {
+			expanded = append(expanded, name)
+			continue
+		}
+		matched := 0
+		for _, model := range models {
+			full := model.String()
+			match := false
+			for _, base := range []string{
+				model.Model,
+				model.Namespace + "/" + model.Model,
+				model.Host + "/" + model.Namespace + "/" + model.Model,
+			} {
+				for _, candidate := range []string{base, base + ":" + model.Tag} {
+					ok, err := path.Match(name, candidate)
+					if err != nil {
+						return nil, fmt.Errorf("invalid model pattern %q: %w", name, err)
+					}
+					match = match || ok
+				}
+			}
+			if !match {
+				continue
+			}
+			matched++
+			if !seen[full] {
+				seen[full] = true
+				expanded = append(expanded, full)
+			}
+		}
+		if matched == 0 {
+			return nil, fmt.Errorf("%w: no models match %q", ErrModelNotFound, name)
+		}
+	}
+	return expanded, nil
+}
+
+// namespaceModels walks manifests/<host>/<namespace>/ and returns every model
+// and tag stored under it. The namespace is given as "namespace" or
+// "host/namespace"; the host defaults to the Ollama registry.
+func namespaceModels(modelPath, namespace string) ([]*ModelName, error) {
+	host, ns, found := strings.Cut(namespace, "/")
+	if !found {
+		host, ns = "registry.ollama.ai", namespace
+	}
+	if host == "" || ns == "" || strings.ContainsAny(ns, "/:") {
+		return nil, fmt.Errorf("invalid namespace %q (expected NAMESPACE or HOST/NAMESPACE)", namespace)
+	}
+
+	namespaceDir := filepath.Join(modelPath, "manifests", host, ns)
+	modelDirs, err := os.ReadDir(namespaceDir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: no models in namespace %s/%s", ErrModelNotFound, host, ns)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read namespace: %w", err)
+	}
+
+	models := []*ModelName{}
+	for _, modelDir := range modelDirs {
+		if !modelDir.IsDir() {
+			continue
+		}
+		tagFiles, err := os.ReadDir(filepath.Join(namespaceDir, modelDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read model directory: %w", err)
+		}
+		for _, tagFile := range tagFiles {
+			if tagFile.IsDir() {
+				continue
+			}
+			models = append(models, &ModelName{Host: host, Namespace: ns, Model: modelDir.Name(), Tag: tagFile.Name()})
 		}
 	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("%w: no models in namespace %s/%s", ErrModelNotFound, host, ns)
+	}
+	return models, nil
+}
 
-	return nil, fmt.Errorf("invalid model name format: %s", name)
+// matchesMediaType reports whether a layer's media type matches pattern, either
+// exactly or by its final component (e.g. "model" matches application/vnd.ollama.image.model)
+func matchesMediaType(mediaType, pattern string) bool {
+	return mediaType == pattern || strings.HasSuffix(mediaType, "."+pattern)
 }
 
-// parseManifest reads and parses the manifest file, returning blob SHAs
-func parseManifest(path string) ([]string, error) {
+// excludeMediaTypes returns a layer filter dropping layers matching any of the
+// given media types, or nil if there is nothing to exclude
+func excludeMediaTypes(mediaTypes []string) func(Layer) bool {
+	if len(mediaTypes) == 0 {
+		return nil
+	}
+	return func(layer Layer) bool {
+		for _, mediaType := range mediaTypes {
+			if matchesMediaType(layer.MediaType, mediaType) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// readBlobList reads blob names from a file, one per line, ignoring blank lines
+// and # comments. Names may be given as sha256-<hex>, sha256:<hex>, or bare hex.
+func readBlobList(path string) (map[string]bool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read manifest: %w", err)
+		return nil, fmt.Errorf("failed to read blob list: %w", err)
 	}
 
-	var manifest Manifest
-	if err := json.Unmarshal(data, &manifest); err != nil {
-		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	blobs := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Accept paths such as blobs/sha256-<hex> from a directory listing
+		name := filepath.Base(line)
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "sha256-"), "sha256:")
+		blobs[blobFileName(name)] = true
 	}
+	return blobs, nil
+}
 
-	shas := []string{}
+// readModelList reads model names from a file, one per line, ignoring blank
+// lines and # comments
+func readModelList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model list: %w", err)
+	}
 
-	// Add config digest
-	if manifest.Config.Digest != "" {
-		shas = append(shas, "sha256-"+strings.TrimPrefix(manifest.Config.Digest, "sha256:"))
+	names := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("model list %s is empty", path)
 	}
+	return names, nil
+}
 
-	// Add layer digests
-	for _, layer := range manifest.Layers {
-		if layer.Digest != "" {
-			shas = append(shas, "sha256-"+strings.TrimPrefix(layer.Digest, "sha256:"))
+// modelBlobs returns the names of the blobs referenced by the named models,
+// including the variant manifests and layers of index manifests
+func modelBlobs(modelPath string, names []string) (map[string]bool, error) {
+	blobs := map[string]bool{}
+	for _, name := range names {
+		resolved, err := resolveModelNames(name, modelPath, false)
+		if err != nil {
+			return nil, err
 		}
+		for _, modelName := range resolved {
+			layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(modelName)))
+			if err != nil {
+				return nil, err
+			}
+			for _, layer := range layers {
+				blobs[layer.BlobName()] = true
+			}
+		}
+	}
+	return blobs, nil
+}
+
+// excludeBlobs returns a layer filter dropping the named blobs, or nil if there is nothing to exclude
+func excludeBlobs(blobs map[string]bool) func(Layer) bool {
+	if len(blobs) == 0 {
+		return nil
 	}
+	return func(layer Layer) bool {
+		return !blobs[layer.BlobName()]
+	}
+}
+
+// noConfig is a layer filter that drops the config blob
+func noConfig(layer Layer) bool {
+	return layer.MediaType != configMediaType
+}
+
+// noLayers is a layer filter that drops every blob, leaving only manifests
+func noLayers(Layer) bool {
+	return false
+}
 
-	return shas, nil
+// printManifests writes the manifest files among relativePaths to w, one JSON
+// document per line
+func printManifests(w io.Writer, modelPath string, relativePaths []string) error {
+	for _, relPath := range relativePaths {
+		if !strings.HasPrefix(filepath.ToSlash(relPath), "manifests/") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(modelPath, relPath))
+		if err != nil {
+			return fmt.Errorf("failed to read manifest: %w", err)
+		}
+		data = bytes.TrimSpace(data)
+		if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// allLayers combines layer filters, keeping only layers every non-nil filter keeps.
+// It returns nil when no filters are set.
+func allLayers(filters ...func(Layer) bool) func(Layer) bool {
+	active := []func(Layer) bool{}
+	for _, filter := range filters {
+		if filter != nil {
+			active = append(active, filter)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	return func(layer Layer) bool {
+		for _, filter := range active {
+			if !filter(layer) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// checkFilesExist makes sure every relative path is present before anything is
+// written, so a missing blob (e.g. from a partially pulled model) is reported up
+// front instead of leaving a truncated tarball behind
+func checkFilesExist(modelPath string, relativePaths []string) error {
+	missing := []string{}
+	for _, relPath := range relativePaths {
+		if _, err := os.Stat(filepath.Join(modelPath, relPath)); err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("failed to stat %s: %w", relPath, err)
+			}
+			missing = append(missing, relPath)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d blob(s) missing from %s, the model may be partially pulled:\n  %s", len(missing), modelPath, strings.Join(missing, "\n  "))
+	}
+	return nil
 }
 
-// getFilePaths returns the relative paths for the manifest and all blobs
-func getFilePaths(modelName *ModelName, modelPath string) ([]string, error) {
-	manifestPath := filepath.Join(
-		modelPath,
-		"manifests",
-		modelName.Host,
-		modelName.Namespace,
-		modelName.Model,
-		modelName.Tag,
-	)
+// totalSize returns the combined on-disk size of the given relative paths
+func totalSize(modelPath string, relativePaths []string) (int64, error) {
+	var total int64
+	for _, relPath := range relativePaths {
+		absPath := filepath.Join(modelPath, relPath)
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat %s: %w", absPath, err)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
 
-	blobShas, err := parseManifest(manifestPath)
+// logSaveSummary logs the number of models, files, and bytes written, followed
+// by a per-model breakdown when there are several models.
+// Files shared between models are counted for the first model that uses them.
+func logSaveSummary(verb, modelPath string, models []*ModelName, keep func(Layer) bool, written []string) error {
+	total, err := totalSize(modelPath, written)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	slog.Info(verb+" models", "models", len(models), "files", len(written), "size", formatBytes(total))
+	if len(models) < 2 {
+		return nil
 	}
 
-	paths := []string{}
+	remaining := map[string]bool{}
+	for _, relPath := range written {
+		remaining[relPath] = true
+	}
+	for _, model := range models {
+		paths, err := getFilePaths(model, modelPath, keep)
+		if err != nil {
+			return err
+		}
+		own := []string{}
+		for _, relPath := range paths {
+			if remaining[relPath] {
+				delete(remaining, relPath)
+				own = append(own, relPath)
+			}
+		}
+		size, err := totalSize(modelPath, own)
+		if err != nil {
+			return err
+		}
+		slog.Info(verb+" model", "model", model.ShortString(), "files", len(own), "size", formatBytes(size))
+	}
+	return nil
+}
 
-	// Add manifest path (relative)
-	paths = append(paths, filepath.Join(
-		"manifests",
-		modelName.Host,
-		modelName.Namespace,
-		modelName.Model,
-		modelName.Tag,
-	))
+// printDryRun writes each relative path with its size, followed by the total, to w
+func printDryRun(w io.Writer, modelPath string, relativePaths []string) error {
+	var total int64
+	for _, relPath := range relativePaths {
+		absPath := filepath.Join(modelPath, relPath)
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", absPath, err)
+		}
+		total += info.Size()
+		fmt.Fprintf(w, "%12d  %s\n", info.Size(), relPath)
+	}
+	fmt.Fprintf(w, "%12d  total (%d files, %s)\n", total, len(relativePaths), formatBytes(total))
+	return nil
+}
 
-	// Add blob paths
-	for _, sha := range blobShas {
-		paths = append(paths, filepath.Join("blobs", sha))
+// newestManifestTime returns the most recent modification time of the manifests
+// among relativePaths, which reproducible tarballs use for every entry
+func newestManifestTime(modelPath string, relativePaths []string) (time.Time, error) {
+	var newest time.Time
+	for _, relPath := range relativePaths {
+		if !strings.HasPrefix(relPath, "manifests"+string(filepath.Separator)) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(modelPath, relPath))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", relPath, err)
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
 	}
+	return newest.Truncate(time.Second), nil
+}
 
-	return paths, nil
+// blobSymlinkHeader returns a symlink header for relPath if it's a blob that is
+// itself a symlink, keeping its target as is, or nil otherwise
+func blobSymlinkHeader(modelPath, relPath string) (*tar.Header, error) {
+	if !strings.HasPrefix(filepath.ToSlash(relPath), "blobs/") {
+		return nil, nil
+	}
+	info, err := os.Lstat(filepath.Join(modelPath, relPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", relPath, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return nil, nil
+	}
+	target, err := os.Readlink(filepath.Join(modelPath, relPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read symlink %s: %w", relPath, err)
+	}
+	header, err := tar.FileInfoHeader(info, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create header for %s: %w", relPath, err)
+	}
+	header.Name = relPath
+	return header, nil
 }
 
-// createTarball creates a tarball from the given paths and writes to stdout
-func createTarball(modelPath string, relativePaths []string) error {
-	tw := tar.NewWriter(os.Stdout)
+// createTarball creates a tarball from the given paths and writes it to w,
+// reporting copied bytes to bar. When reproducible is set, entries are sorted
+// and their headers carry no ownership and the newest manifest's mtime, so the
+// same models always produce the same bytes. Unless followSymlinks is set,
+// symlinked blobs are archived as symlinks instead of their targets' content.
+// With noatime, files are read without updating their access times where the
+// platform allows it. Canceling ctx stops it between files or partway through
+// copying one.
+func createTarball(ctx context.Context, w io.Writer, modelPath string, relativePaths []string, bar *progressBar, reproducible, xattrs, followSymlinks, noatime bool) error {
+	tw := tar.NewWriter(w)
 	defer tw.Close()
 
+	var modTime time.Time
+	if reproducible {
+		relativePaths = slices.Sorted(slices.Values(relativePaths))
+		var err error
+		if modTime, err = newestManifestTime(modelPath, relativePaths); err != nil {
+			return err
+		}
+	}
+
 	for _, relPath := range relativePaths {
-		absPath := filepath.Join(modelPath, relPath)
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+
+		if !followSymlinks {
+			header, err := blobSymlinkHeader(modelPath, relPath)
+			if err != nil {
+				return err
+			}
+			if header != nil {
+				if reproducible {
+					header.Uid, header.Gid = 0, 0
+					header.Uname, header.Gname = "", ""
+					header.ModTime = modTime
+					header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+				}
+				if err := tw.WriteHeader(header); err != nil {
+					return fmt.Errorf("failed to write header for %s: %w", relPath, err)
+				}
+				if verbose {
+					slog.Info("Archived symlink", "name", relPath, "target", header.Linkname)
+				}
+				bar.File(relPath)
+				continue
+			}
+		}
+
+		// Resolve symlinked blobs and manifests (common with shared model stores)
+		// so the target's content is archived as a regular file
+		absPath, err := filepath.EvalSymlinks(filepath.Join(modelPath, relPath))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", relPath, err)
+		}
 
 		// Get file info
 		info, err := os.Stat(absPath)
@@ -167,73 +646,732 @@ func createTarball(modelPath string, relativePaths []string) error {
 		// Use relative path in tarball
 		header.Name = relPath
 
+		if reproducible {
+			header.Uid, header.Gid = 0, 0
+			header.Uname, header.Gname = "", ""
+			header.Mode = 0o644
+			header.ModTime = modTime
+			header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+		}
+
+		// Record extended attributes, such as SELinux labels, as PAX records
+		if xattrs {
+			attrs, err := readXattrs(absPath)
+			if err != nil {
+				return err
+			}
+			for name, value := range attrs {
+				if header.PAXRecords == nil {
+					header.PAXRecords = map[string]string{}
+				}
+				header.PAXRecords[paxXattrPrefix+name] = value
+			}
+		}
+
 		// Write header
 		if err := tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("failed to write header for %s: %w", relPath, err)
 		}
 
 		// Write file content
-		file, err := os.Open(absPath)
+		file, err := openForArchive(absPath, noatime)
 		if err != nil {
 			return fmt.Errorf("failed to open %s: %w", absPath, err)
 		}
 
-		if _, err := io.Copy(tw, file); err != nil {
+		if _, err := io.Copy(io.MultiWriter(tw, bar), &contextReader{ctx: ctx, r: file}); err != nil {
 			file.Close()
 			return fmt.Errorf("failed to write %s to tarball: %w", relPath, err)
 		}
 		file.Close()
+
+		if verbose {
+			slog.Info("Archived file", "name", relPath, "size", formatBytes(header.Size))
+		}
+		bar.File(relPath)
 	}
+	bar.Finish()
 
 	return nil
 }
 
 var saveCmd = &cobra.Command{
-	Use:   "save MODEL_NAME",
-	Short: "Save an Ollama model to a tarball",
-	Long: `Save an Ollama model by creating a tarball containing its manifest and blob files.
+	Use:   "save MODEL_NAME [MODEL_NAME...]",
+	Short: "Save Ollama models to a tarball",
+	Long: `Save one or more Ollama models by creating a tarball containing their manifests and blob files.
+Blobs shared between models are only stored once.
 The tarball is written to stdout, so you can redirect it to a file or pipe it elsewhere.
+To avoid filling a terminal with binary data, save refuses to write to stdout
+when it is a terminal; pass --allow-tty to write anyway.
+A program running ollie can instead pass a pipe on another descriptor with
+--output-fd N, keeping stdout free for other output.
+Use -o/--output to write directly to a file instead, or --output-dir DIR to
+write a separate tarball for each model, named DIR/<namespace>_<model>_<tag>.tar
+with the extension for --compress, so models can be restored individually.
+Before writing, the estimated (uncompressed) size is printed to stderr unless
+--quiet is set.
+
+The tarball can be compressed with --compress (none, gzip, xz, bzip2, or zstd),
+with --level (or --compression-level) setting the compression level for gzip,
+bzip2, and zstd. When writing to a file without --compress, the format is
+inferred from the file extension (.tar.gz, .tar.xz, .tar.bz2, .tar.zst). bzip2
+compression uses the bzip2 command, which must be in PATH.
+
+Levels are 1-9 for gzip and bzip2 and 1-22 for zstd; xz has no levels, so
+--level is rejected for it.
+
+Compression is often the bottleneck for large models, so gzip and zstd output
+is compressed on --compress-threads threads, one per CPU by default. Parallel
+gzip compresses 1 MiB blocks at a time and still writes a single ordinary gzip
+stream. xz and bzip2 always compress on one thread.
+
+A model given without a tag resolves to :latest, or to its only tag if there is
+no latest. Use --all-tags to save every tag of such a model instead. A manifest
+can also be pinned by digest (or a digest prefix) with MODEL@sha256:<digest>,
+or given by file path with --manifest instead of a model name. Use --from-file
+to read model names from a file, one per line, with blank lines and # comments
+ignored; they're saved along with any models given as arguments.
+Use --namespace to save every model and tag under a namespace, such as
+"library" or "myhost.com/myorg", into one tarball.
+
+A model argument containing *, ?, or [ is a glob pattern matched against the
+local models' names, with or without their host, namespace, and tag, and is
+replaced by every model and tag it matches: "llama*" saves llama2:latest,
+llama3:8b, and so on. Quote patterns so the shell doesn't expand them against
+files in the current directory. A pattern that matches nothing is an error.
+
+A manifest may be an index (OCI image index or Docker manifest list) of
+variants, such as one per quantization. Its only variant is saved, or the one
+named with --variant, which is required when there are several; the error
+lists the choices. Variants are named by their platform variant, their
+org.opencontainers.image.ref.name annotation, or their os/architecture.
+
+Use --exclude-layer to leave out layers by media type, e.g. to share a model's
+template and parameters without its weights. Either the full media type or its
+last component (such as "model") can be given.
+
+--no-config leaves out the config blob. The manifests still reference it, so
+the archive is intentionally incomplete: load writes the manifests, but Ollama
+can't use the models until the config blob is put back (e.g. with a later
+delta save). A warning is printed as a reminder.
+
+For delta syncs, --exclude-blobs FILE leaves out blobs the receiver already has.
+The file lists one blob per line, e.g. the output of ls on the remote blobs
+directory. Manifests are always included.
+--include-blobs-from MODEL (repeatable) does the same relative to local models:
+the tarball only includes blobs that none of those models reference, e.g. to
+ship a fine-tune to someone who already has its base model. The saved models'
+manifests are still included in full.
+
+Use --manifest-only to archive just the manifests, without any blobs, to share
+what a model is made of without moving its weights. --print writes the manifest
+JSON to stdout instead of a tarball, one model per line.
+
+Use --split SIZE with -o to write the tarball as numbered parts of at most SIZE
+(e.g. 2GB or 500MiB) for media or uploads with a size limit: -o backup.tar
+writes backup.tar.001, backup.tar.002, and so on, and "ollie load
+backup.tar.001" reads them back in order. With --checksum, the sha256 of the
+whole tarball is written to backup.tar.sha256.
+
+Use --append FILE to add models to an existing uncompressed tarball instead
+of writing a new one. Entries already in the tarball, such as shared blobs, are
+not written again; a manifest that's already there is kept as it is.
+
+With --preserve-xattrs, extended attributes of the files (such as SELinux
+labels) are recorded as PAX records, which load --preserve-xattrs restores.
+This is only supported on Linux.
+
+Symlinked blobs and manifests (common with shared model stores) are resolved
+and their targets' content is archived. With --no-follow-symlinks, symlinked
+blobs are archived as symlinks with their targets kept as they are, e.g. to
+keep pointing at a shared read-only store; the targets aren't included.
+load only extracts regular files and rejects symlink entries, so such a
+tarball can't be loaded with ollie; unpack it with tar -x on a machine where
+the link targets exist.
+
+On Linux, files are read with O_NOATIME so a save doesn't update the blobs'
+access times, which can otherwise set off backup or tiering jobs on large
+shared stores. This only works for files ollie's user owns; others are read
+normally. Pass --noatime=false to read them all normally.
+
+With --reproducible, entries are sorted and their headers drop ownership and use
+the newest manifest's mtime, so saving the same models always yields the same bytes.
+
+Interrupting a save (Ctrl-C) or exceeding --timeout stops it cleanly and
+removes the partly written output file; an --append tarball is cut back to
+what it held before.
 
 Examples:
   ollie save llama2 > llama2.tar
+  ollie save llama2 -o llama2.tar
+  ollie save llama2 -o llama2.tar.gz
+  ollie save llama2 --compress xz > llama2.tar.xz
+  ollie save llama2 -o llama2.tar.zst --level 19
   ollie save library/llama2:latest > llama2.tar
-  ollie save registry.ollama.ai/library/llama2:latest > llama2.tar`,
-	Args: cobra.ExactArgs(1),
+  ollie save registry.ollama.ai/library/llama2:latest > llama2.tar
+  ollie save llama2 mistral codellama > bundle.tar
+  ollie save 'llama*' -o llamas.tar
+  ollie save --output-dir backups/ --compress zstd llama2 mistral
+  ollie save --from-file models.txt -o backup.tar
+  ollie save --namespace library -o library.tar
+  ollie save --append backup.tar mistral
+  ollie save --dry-run llama2
+  ollie save llama2 --all-tags > llama2-all.tar
+  ollie save llama2@sha256:78e26419b446 > llama2.tar
+  ollie save llama2 --exclude-layer application/vnd.ollama.image.model > llama2-meta.tar
+  ollie save llama2 --no-config > llama2-noconfig.tar
+  ollie save llama2 --exclude-blobs remote-blobs.txt > llama2-delta.tar
+  ollie save mymodel:tuned --include-blobs-from llama2 -o tuned-delta.tar
+  ollie save llama2 --manifest-only -o llama2-manifest.tar
+  ollie save llama2 --print | jq .layers
+  ollie save llama2 --reproducible -o llama2.tar
+  ollie save llama2 --no-follow-symlinks -o llama2-links.tar
+  ollie save llama2 -o llama2.tar --timeout 1h
+  ollie save llama2 -o llama2.tar --split 2GB
+  ollie save llama2 --output-fd 3 3>llama2.tar
+  ollie save mixtral --variant q4_0 -o mixtral-q4_0.tar
+  ollie save --manifest ~/.ollama/models/manifests/registry.ollama.ai/library/llama2/latest > llama2.tar`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if saveManifest != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		if saveFromFile != "" || saveNamespace != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: completeModelNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		modelNameStr := args[0]
-
 		// Check if stdout is a terminal
-		if term.IsTerminal(int(os.Stdout.Fd())) {
-			return fmt.Errorf("refusing to write binary tarball to terminal\nPlease redirect output to a file: ollie save %s > output.tar", modelNameStr)
+		stdout, err := saveStdout()
+		if err != nil {
+			return err
+		}
+		if saveOutput == "" && saveOutputDir == "" && saveAppend == "" && !saveDryRun && !savePrint && !saveAllowTTY && term.IsTerminal(int(stdout.Fd())) {
+			return fmt.Errorf("refusing to write binary tarball to terminal\nPlease redirect output to a file: ollie save %s > output.tar (or pass --allow-tty)", strings.Join(args, " "))
 		}
 
-		// Parse model name
-		modelName, err := parseModelName(modelNameStr)
-		if err != nil {
+		var modelPath string
+		var filePaths []string
+		var models []*ModelName
+		var skipBlobs map[string]bool
+		if saveExcludeBlobs != "" {
+			var err error
+			if skipBlobs, err = readBlobList(saveExcludeBlobs); err != nil {
+				return err
+			}
+		}
+		if len(saveIncludeBlobsFrom) > 0 {
+			// Base models are looked up in the same models directory as the saved ones
+			basePath, err := getOllamaModelsPath()
+			if saveManifest != "" {
+				basePath, _, err = modelFromManifestPath(saveManifest)
+			}
+			if err != nil {
+				return err
+			}
+			baseBlobs, err := modelBlobs(basePath, saveIncludeBlobsFrom)
+			if err != nil {
+				return err
+			}
+			if skipBlobs == nil {
+				skipBlobs = map[string]bool{}
+			}
+			for name := range baseBlobs {
+				skipBlobs[name] = true
+			}
+			slog.Info("Leaving out blobs of base models", "models", len(saveIncludeBlobsFrom), "blobs", len(baseBlobs))
+		}
+		keep := allLayers(excludeMediaTypes(saveExcludeLayers), excludeBlobs(skipBlobs))
+		if saveNoConfig {
+			slog.Warn("--no-config leaves the config blob out, so the tarball is incomplete and Ollama can't use the models until the config is restored")
+			keep = allLayers(keep, noConfig)
+		}
+		if saveManifestOnly || savePrint {
+			keep = noLayers
+		}
+
+		if saveManifest != "" {
+			// Derive the model and models directory from the manifest's location
+			var modelName *ModelName
+			var err error
+			modelPath, modelName, err = modelFromManifestPath(saveManifest)
+			if err != nil {
+				return err
+			}
+			modelName.Variant = saveVariant
+			if filePaths, err = getFilePaths(modelName, modelPath, keep); err != nil {
+				return err
+			}
+			models = []*ModelName{modelName}
+		} else {
+			// Get model path from environment or use default
+			var err error
+			modelPath, err = getOllamaModelsPath()
+			if err != nil {
+				return err
+			}
+
+			// Add the models listed in --from-file to those given as arguments
+			names := args
+			if saveFromFile != "" {
+				listed, err := readModelList(saveFromFile)
+				if err != nil {
+					return err
+				}
+				names = append(slices.Clone(args), listed...)
+			}
+
+			// Expand glob patterns into the models they match
+			if names, err = expandModelGlobs(modelPath, names); err != nil {
+				return err
+			}
+
+			// Add every model stored under --namespace
+			if saveNamespace != "" {
+				namespaced, err := namespaceModels(modelPath, saveNamespace)
+				if err != nil {
+					return err
+				}
+				names = slices.Clone(names)
+				for _, model := range namespaced {
+					names = append(names, model.String())
+				}
+			}
+
+			// Get file paths for every model
+			filePaths, models, err = collectFilePaths(names, modelPath, saveAllTags, saveVariant, keep)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := checkFilesExist(modelPath, filePaths); err != nil {
 			return err
 		}
 
-		// Get model path from environment or use default
-		modelPath, err := getOllamaModelsPath()
+		if savePrint {
+			return printManifests(os.Stdout, modelPath, filePaths)
+		}
+
+		// Appending leaves out entries the tarball already has
+		var appendFile *os.File
+		var appendOffset int64
+		if saveAppend != "" {
+			var err error
+			flags := os.O_RDWR
+			if saveDryRun {
+				flags = os.O_RDONLY
+			}
+			if appendFile, err = os.OpenFile(saveAppend, flags, 0); err != nil {
+				return fmt.Errorf("failed to open tarball: %w", err)
+			}
+			defer appendFile.Close()
+
+			existing, end, err := scanTarballEnd(appendFile)
+			if err != nil {
+				return fmt.Errorf("%s: %w", saveAppend, err)
+			}
+			appendOffset = end
+
+			remaining := []string{}
+			for _, relPath := range filePaths {
+				if !existing[filepath.ToSlash(relPath)] {
+					remaining = append(remaining, relPath)
+				} else if strings.HasPrefix(filepath.ToSlash(relPath), "manifests/") {
+					slog.Warn("manifest already in tarball, not appending it", "path", relPath)
+				}
+			}
+			filePaths = remaining
+		}
+
+		// Only list what would be archived
+		if saveDryRun {
+			return printDryRun(os.Stderr, modelPath, filePaths)
+		}
+
+		// Stop cleanly on interrupt or once --timeout elapses
+		ctx, cancel := commandContext(cmd.Context(), saveTimeout)
+		defer cancel()
+
+		// Compute the total size up front for progress reporting
+		size, err := totalSize(modelPath, filePaths)
 		if err != nil {
 			return err
 		}
 
-		// Get file paths
-		filePaths, err := getFilePaths(modelName, modelPath)
-		if err != nil {
+		// Infer compression from the output file name unless set explicitly
+		compression := saveCompress
+		if !cmd.Flags().Changed("compress") && saveOutput != "" {
+			compression = compressionFromFileName(saveOutput)
+		}
+		if appendFile != nil {
+			compression = compressNone
+		}
+		if err := checkCompressionLevel(compression, saveLevel); err != nil {
+			return err
+		}
+
+		// Report the size up front, e.g. before streaming over a slow link
+		if !quiet {
+			if compression != compressNone {
+				slog.Info("Estimated size", "size", formatBytes(size), "uncompressed", true)
+			} else {
+				slog.Info("Estimated size", "size", formatBytes(size))
+			}
+		}
+
+		if saveSplit != "" && saveOutput == "" {
+			return fmt.Errorf("--split requires -o/--output to name the parts")
+		}
+
+		if saveBufferSize <= 0 {
+			return fmt.Errorf("invalid --buffer-size %d: must be positive", saveBufferSize)
+		}
+
+		if appendFile != nil {
+			if _, err := appendFile.Seek(appendOffset, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek in tarball: %w", err)
+			}
+			bw := bufio.NewWriterSize(appendFile, saveBufferSize)
+			bar := newProgressBar("Appending", size, quiet || verbose)
+			if err := createTarball(ctx, bw, modelPath, filePaths, bar, saveReproducible, savePreserveXattrs, !saveNoFollowSymlinks, saveNoatime); err != nil {
+				// Cut off the partly appended entries, leaving the tarball as it was
+				if truncErr := appendFile.Truncate(appendOffset); truncErr != nil {
+					slog.Warn("failed to restore tarball after error", "path", saveAppend, "error", truncErr)
+				}
+				return err
+			}
+			if err := bw.Flush(); err != nil {
+				return fmt.Errorf("failed to flush output: %w", err)
+			}
+
+			// Drop any padding the old tarball had past its end-of-archive blocks
+			end, err := appendFile.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return fmt.Errorf("failed to read tarball offset: %w", err)
+			}
+			if err := appendFile.Truncate(end); err != nil {
+				return fmt.Errorf("failed to truncate tarball: %w", err)
+			}
+			if err := appendFile.Close(); err != nil {
+				return err
+			}
+			if err := logSaveSummary("Appended", modelPath, models, keep, filePaths); err != nil {
+				return err
+			}
+
+			if saveChecksum {
+				sum, err := fileSHA256(saveAppend)
+				if err != nil {
+					return fmt.Errorf("failed to hash tarball: %w", err)
+				}
+				return writeChecksum(saveAppend, sum)
+			}
+			return nil
+		}
+
+		// Write one tarball per model into --output-dir
+		if saveOutputDir != "" {
+			if err := os.MkdirAll(saveOutputDir, os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+			for _, model := range models {
+				modelPaths, err := getFilePaths(model, modelPath, keep)
+				if err != nil {
+					return err
+				}
+				output := filepath.Join(saveOutputDir, modelTarballName(model)+tarballExtension(compression))
+				if err := saveTarballFile(ctx, output, modelPath, modelPaths, compression); err != nil {
+					return err
+				}
+				written, err := totalSize(modelPath, modelPaths)
+				if err != nil {
+					return err
+				}
+				slog.Info("Saved model", "model", model.ShortString(), "output", output, "files", len(modelPaths), "size", formatBytes(written))
+			}
+			return nil
+		}
+
+		if err := saveTarballFile(ctx, saveOutput, modelPath, filePaths, compression); err != nil {
 			return err
 		}
+		return logSaveSummary("Saved", modelPath, models, keep, filePaths)
+	},
+}
+
+// modelTarballName returns the file name, without extension, that --output-dir
+// uses for a model: namespace_model_tag, prefixed with the host when it isn't
+// the default registry
+func modelTarballName(modelName *ModelName) string {
+	name := modelName.Namespace + "_" + modelName.Model + "_" + modelName.Tag
+	if modelName.Host != "registry.ollama.ai" {
+		name = strings.ReplaceAll(modelName.Host, ":", "-") + "_" + name
+	}
+	return name
+}
+
+// saveTarballFile writes a tarball of the relative paths to output, or to stdout
+// when output is empty, compressing it with the given format and writing its
+// checksum when --checksum is set. With --split, output names the parts
+// instead. A partly written output is removed if writing fails or ctx is canceled.
+func saveTarballFile(ctx context.Context, output, modelPath string, relativePaths []string, compression string) (err error) {
+	size, err := totalSize(modelPath, relativePaths)
+	if err != nil {
+		return err
+	}
 
-		// Create tarball
-		if err := createTarball(modelPath, filePaths); err != nil {
+	// Open output destination, split into volumes when requested
+	var out io.WriteCloser
+	remove := func() { os.Remove(output) }
+	if saveSplit != "" {
+		partSize, err := parseSize(saveSplit)
+		if err != nil {
+			return fmt.Errorf("invalid --split: %w", err)
+		}
+		split, err := newSplitWriter(ctx, output, partSize, saveForce)
+		if err != nil {
 			return err
 		}
+		out, remove = split, split.Remove
+	} else if out, err = openSaveOutput(output, saveForce); err != nil {
+		return err
+	}
+	defer out.Close()
+	if output != "" {
+		defer func() {
+			if err != nil {
+				out.Close()
+				remove()
+			}
+		}()
+	}
+
+	// Hash the final output bytes when a checksum was requested
+	var dest io.Writer = out
+	hasher := sha256.New()
+	if saveChecksum {
+		dest = io.MultiWriter(out, hasher)
+	}
+
+	// Buffer writes to the destination to avoid many small syscalls
+	bw := bufio.NewWriterSize(dest, saveBufferSize)
+
+	cw, err := newCompressWriter(bw, compression, saveLevel, saveCompressThreads)
+	if err != nil {
+		return err
+	}
+
+	// Create tarball
+	bar := newProgressBar("Saving", size, quiet || verbose)
+	if err := createTarball(ctx, cw, modelPath, relativePaths, bar, saveReproducible, savePreserveXattrs, !saveNoFollowSymlinks, saveNoatime); err != nil {
+		cw.Close()
+		return err
+	}
+
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("failed to finish compressed output: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if saveChecksum {
+		return writeChecksum(output, hex.EncodeToString(hasher.Sum(nil)))
+	}
+	return nil
+}
 
+var (
+	saveOutput           string
+	saveForce            bool
+	saveCompress         string
+	saveLevel            int
+	saveCompressThreads  int
+	saveDryRun           bool
+	saveBufferSize       int
+	saveAllTags          bool
+	saveExcludeLayers    []string
+	saveNoConfig         bool
+	saveChecksum         bool
+	saveReproducible     bool
+	saveManifest         string
+	saveFromFile         string
+	saveAppend           string
+	saveExcludeBlobs     string
+	saveIncludeBlobsFrom []string
+	saveNamespace        string
+	saveManifestOnly     bool
+	savePrint            bool
+	saveVariant          string
+	saveOutputDir        string
+	saveAllowTTY         bool
+	savePreserveXattrs   bool
+	saveNoFollowSymlinks bool
+	saveNoatime          bool
+	saveTimeout          time.Duration
+	saveSplit            string
+	saveOutputFD         int
+)
+
+// nopWriteCloser wraps a writer that must not be closed, such as os.Stdout
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// writeChecksum records the tarball's sha256 in <output>.sha256 using the
+// sha256sum format, or prints it to stderr when the tarball went to stdout
+func writeChecksum(output, sum string) error {
+	if output == "" {
+		fmt.Fprintf(os.Stderr, "sha256: %s\n", sum)
 		return nil
-	},
+	}
+
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(output))
+	if err := os.WriteFile(output+".sha256", []byte(line), 0o644); err != nil {
+		return fmt.Errorf("failed to write checksum file: %w", err)
+	}
+	return nil
+}
+
+// scanTarballEnd reads the entries of an uncompressed tarball and returns their
+// names along with the offset just past the last entry's content, where the
+// end-of-archive zero blocks begin and new entries can be appended
+func scanTarballEnd(file *os.File) (map[string]bool, int64, error) {
+	if detectCompression(bufio.NewReader(file)) != compressNone {
+		return nil, 0, fmt.Errorf("can only append to uncompressed tarballs")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("failed to rewind tarball: %w", err)
+	}
+
+	names := map[string]bool{}
+	var end int64
+	tarReader := tar.NewReader(file)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read tar header: %w", err)
+		}
+		names[path.Clean(header.Name)] = true
+
+		// The tar reader reads headers straight from the file, so the file offset
+		// is now where this entry's content starts. Content is padded to 512 bytes.
+		start, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read tarball offset: %w", err)
+		}
+		end = start + (header.Size+511)/512*512
+	}
+
+	return names, end, nil
+}
+
+// saveStdout returns the file save writes to when no output file is given:
+// the file descriptor from --output-fd, or stdout
+func saveStdout() (*os.File, error) {
+	if saveOutputFD < 0 {
+		return os.Stdout, nil
+	}
+	file := os.NewFile(uintptr(saveOutputFD), fmt.Sprintf("fd %d", saveOutputFD))
+	if file == nil {
+		return nil, fmt.Errorf("invalid --output-fd %d", saveOutputFD)
+	}
+	if _, err := file.Stat(); err != nil {
+		return nil, fmt.Errorf("invalid --output-fd %d: %w", saveOutputFD, err)
+	}
+	return file, nil
+}
+
+// openSaveOutput returns the writer the tarball should be written to.
+// An empty path means stdout, or the --output-fd descriptor, which is closed
+// once the tarball is written so the reader sees its end. Existing files are
+// only overwritten when force is set.
+func openSaveOutput(path string, force bool) (io.WriteCloser, error) {
+	if path == "" {
+		stdout, err := saveStdout()
+		if err != nil {
+			return nil, err
+		}
+		if stdout == os.Stdout {
+			return nopWriteCloser{os.Stdout}, nil
+		}
+		return stdout, nil
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("output file %s already exists (use --force to overwrite)", path)
+		}
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
 }
 
 func init() {
+	saveCmd.Flags().StringVarP(&saveOutput, "output", "o", "", "write the tarball to FILE instead of stdout")
+	saveCmd.Flags().BoolVarP(&saveForce, "force", "f", false, "overwrite the output file if it already exists")
+	saveCmd.Flags().StringVar(&saveCompress, "compress", compressNone, "compression format: none, gzip, xz, bzip2, or zstd")
+	saveCmd.Flags().IntVar(&saveLevel, "level", 0, "compression level for gzip, bzip2, or zstd (0 uses the default)")
+	saveCmd.Flags().IntVar(&saveLevel, "compression-level", 0, "same as --level")
+	saveCmd.Flags().IntVar(&saveCompressThreads, "compress-threads", runtime.GOMAXPROCS(0), "number of threads compressing gzip or zstd output")
+	saveCmd.Flags().BoolVar(&saveDryRun, "dry-run", false, "list the files that would be archived without writing a tarball")
+	saveCmd.Flags().IntVar(&saveBufferSize, "buffer-size", 1<<20, "size in bytes of the output write buffer")
+	saveCmd.Flags().BoolVar(&saveAllTags, "all-tags", false, "save every tag of models given without an explicit tag")
+	saveCmd.Flags().StringArrayVar(&saveExcludeLayers, "exclude-layer", nil, "leave out layers with this media type (repeatable)")
+	saveCmd.Flags().BoolVar(&saveNoConfig, "no-config", false, "leave out the config blob (the tarball is incomplete)")
+	saveCmd.Flags().BoolVar(&saveReproducible, "reproducible", false, "sort entries and normalize ownership and mtimes so identical models produce identical tarballs")
+	saveCmd.Flags().BoolVar(&saveChecksum, "checksum", false, "write the tarball's sha256 to OUTPUT.sha256 (or stderr when writing to stdout)")
+	saveCmd.Flags().StringVar(&saveAppend, "append", "", "append the models to the existing uncompressed tarball FILE")
+	saveCmd.MarkFlagsMutuallyExclusive("append", "output")
+	saveCmd.MarkFlagsMutuallyExclusive("append", "compress")
+	saveCmd.MarkFlagsMutuallyExclusive("level", "compression-level")
+	saveCmd.Flags().StringVar(&saveFromFile, "from-file", "", "also save the models listed in FILE (one per line, # comments allowed)")
+	saveCmd.Flags().StringVar(&saveNamespace, "namespace", "", "also save every model under NAMESPACE or HOST/NAMESPACE")
+	saveCmd.Flags().StringVar(&saveManifest, "manifest", "", "save the model whose manifest is at PATH instead of naming it")
+	saveCmd.Flags().StringVar(&saveExcludeBlobs, "exclude-blobs", "", "leave out the blobs listed in FILE (one sha256 name per line)")
+	saveCmd.Flags().StringArrayVar(&saveIncludeBlobsFrom, "include-blobs-from", nil, "leave out blobs that MODEL also references, for receivers that already have it (repeatable)")
+	saveCmd.Flags().BoolVar(&saveManifestOnly, "manifest-only", false, "archive only the manifests, without any blobs")
+	saveCmd.Flags().BoolVar(&savePrint, "print", false, "print the manifest JSON to stdout instead of writing a tarball")
+	saveCmd.Flags().StringVar(&saveVariant, "variant", "", "variant to save from models whose manifest is an index of variants (e.g. q4_0)")
+	saveCmd.Flags().StringVar(&saveOutputDir, "output-dir", "", "write one tarball per model into DIR, named namespace_model_tag.tar")
+	saveCmd.Flags().BoolVar(&saveAllowTTY, "allow-tty", false, "write the tarball to stdout even when it is a terminal")
+	saveCmd.Flags().StringVar(&saveSplit, "split", "", "split the tarball into parts of at most SIZE, e.g. 2GB, written as OUTPUT.001, OUTPUT.002, ...")
+	for _, flag := range []string{"append", "output-dir", "print"} {
+		saveCmd.MarkFlagsMutuallyExclusive("split", flag)
+	}
+	saveCmd.Flags().IntVar(&saveOutputFD, "output-fd", -1, "write the tarball to the already open file descriptor N instead of stdout")
+	for _, flag := range []string{"output", "output-dir", "append", "print", "split"} {
+		saveCmd.MarkFlagsMutuallyExclusive("output-fd", flag)
+	}
+	saveCmd.Flags().DurationVar(&saveTimeout, "timeout", 0, "abort the save if it takes longer than this, e.g. 30m (0 means no limit)")
+	saveCmd.Flags().BoolVar(&savePreserveXattrs, "preserve-xattrs", false, "record extended attributes such as SELinux labels in the tarball (Linux only)")
+	saveCmd.Flags().BoolVar(&saveNoFollowSymlinks, "no-follow-symlinks", false, "archive symlinked blobs as symlinks instead of their targets' content")
+	saveCmd.Flags().BoolVar(&saveNoatime, "noatime", runtime.GOOS == "linux", "read files without updating their access times (Linux only)")
+	saveCmd.MarkFlagsMutuallyExclusive("output-dir", "output")
+	saveCmd.MarkFlagsMutuallyExclusive("output-dir", "append")
+	saveCmd.MarkFlagsMutuallyExclusive("output-dir", "print")
+	saveCmd.MarkFlagsMutuallyExclusive("manifest", "namespace")
+	saveCmd.MarkFlagsMutuallyExclusive("print", "output")
+	saveCmd.MarkFlagsMutuallyExclusive("print", "append")
+	saveCmd.MarkFlagsMutuallyExclusive("print", "compress")
+	saveCmd.MarkFlagsMutuallyExclusive("print", "dry-run")
 	rootCmd.AddCommand(saveCmd)
 }
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// saveTestTarball archives the named models of modelPath the way save does and
+// writes the tarball to a file, returning its path
+func saveTestTarball(t *testing.T, modelPath string, compression string, names ...string) string {
+	t.Helper()
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		modelName, err := parseModelName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modelPaths, err := getFilePaths(modelName, modelPath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, relPath := range modelPaths {
+			if !seen[relPath] {
+				seen[relPath] = true
+				paths = append(paths, relPath)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	cw, err := newCompressWriter(&buf, compression, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := createTarball(context.Background(), cw, modelPath, paths, nil, false, false, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tarball := filepath.Join(t.TempDir(), "models"+tarballExtension(compression))
+	if err := os.WriteFile(tarball, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return tarball
+}
+
+// testExtractOptions returns the options load uses by default, without chown
+func testExtractOptions() extractOptions {
+	return extractOptions{Quiet: true, Strict: true, UID: -1, GID: -1}
+}
+
+// BenchmarkSaveBufferSize writes an uncompressed tarball to a file directly
+// and through a --buffer-size write buffer
+func BenchmarkSaveBufferSize(b *testing.B) {
+	modelPath := newTestStore(b)
+	layers := []testLayer{{modelMediaType, string(benchData(16 << 20))}}
+	for i := range 50 {
+		layers = append(layers, testLayer{paramsMediaType, fmt.Sprintf(`{"seed":%d}`, i)})
+	}
+	addTestModel(b, modelPath, "llama2:7b", layers...)
+	modelName, _ := parseModelName("llama2:7b")
+	paths, err := getFilePaths(modelName, modelPath, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	size, err := totalSize(modelPath, paths)
+	if err != nil {
+		b.Fatal(err)
+	}
+	output := filepath.Join(b.TempDir(), "models.tar")
+
+	for _, bufferSize := range []int{0, 1 << 20} {
+		b.Run(fmt.Sprintf("buffer-size=%d", bufferSize), func(b *testing.B) {
+			b.SetBytes(size)
+			for b.Loop() {
+				out, err := os.Create(output)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var w io.Writer = out
+				var bw *bufio.Writer
+				if bufferSize > 0 {
+					bw = bufio.NewWriterSize(out, bufferSize)
+					w = bw
+				}
+				if err := createTarball(context.Background(), w, modelPath, paths, nil, false, false, true, false); err != nil {
+					b.Fatal(err)
+				}
+				if bw != nil {
+					if err := bw.Flush(); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := out.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression string
+		models      []string
+	}{
+		{"single model", compressNone, []string{"llama2:7b"}},
+		{"shared blobs", compressNone, []string{"llama2:7b", "myorg/tuned:v1"}},
+		{"gzip", compressGzip, []string{"llama2:7b"}},
+		{"xz", compressXz, []string{"llama2:7b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newTestStore(t)
+			addDefaultTestModel(t, src, "llama2:7b")
+			// Shares its template and params blobs with llama2:7b
+			addTestModel(t, src, "myorg/tuned:v1",
+				testLayer{modelMediaType, "tuned weights"},
+				testLayer{templateMediaType, "{{ .Prompt }}"},
+				testLayer{paramsMediaType, `{"stop":["</s>"]}`},
+			)
+
+			tarball := saveTestTarball(t, src, tt.compression, tt.models...)
+			dest := newTestStore(t)
+			if err := extractTarball(context.Background(), tarball, dest, testExtractOptions()); err != nil {
+				t.Fatalf("extractTarball: %v", err)
+			}
+
+			// Every file of the saved models comes back byte for byte
+			want := readStoreFiles(t, src)
+			got := readStoreFiles(t, dest)
+			for _, name := range tt.models {
+				modelName, _ := parseModelName(name)
+				paths, err := getFilePaths(modelName, src, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, relPath := range paths {
+					relPath = filepath.ToSlash(relPath)
+					if got[relPath] != want[relPath] {
+						t.Errorf("%s differs after round trip: got %q, want %q", relPath, got[relPath], want[relPath])
+					}
+				}
+			}
+			for relPath := range got {
+				if _, ok := want[relPath]; !ok {
+					t.Errorf("unexpected file after round trip: %s", relPath)
+				}
+			}
+			if len(tt.models) == 1 && len(got) >= len(want) {
+				t.Errorf("loaded %d files, want only the %s files", len(got), tt.models[0])
+			}
+		})
+	}
+}
+
+// testTarEntry is a header and the content read from a tarball
+type testTarEntry struct {
+	Header *tar.Header
+	Body   string
+}
+
+// readTestTar returns the entries of an uncompressed tarball by name
+func readTestTar(t *testing.T, tarball string) map[string]testTarEntry {
+	t.Helper()
+	file, err := os.Open(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	entries := map[string]testTarEntry{}
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[header.Name] = testTarEntry{Header: header, Body: string(body)}
+	}
+}
+
+func TestSaveResolvesSymlinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		relative bool
+		manifest bool
+		dangling bool
+	}{
+		{name: "absolute blob link"},
+		{name: "relative blob link", relative: true},
+		{name: "manifest link", manifest: true},
+		{name: "dangling link", dangling: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelPath := newTestStore(t)
+			manifest := addDefaultTestModel(t, modelPath, "llama2:7b")
+			want := readStoreFiles(t, modelPath)
+
+			// Move the weights (or the manifest) into a shared store and link to it
+			shared := t.TempDir()
+			rel := filepath.Join("blobs", manifest.Layers[0].BlobName())
+			if tt.manifest {
+				rel = manifestRelPath(&ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "llama2", Tag: "7b"})
+			}
+			link := filepath.Join(modelPath, rel)
+			target := filepath.Join(shared, filepath.Base(rel))
+			if err := os.Rename(link, target); err != nil {
+				t.Fatal(err)
+			}
+			if tt.dangling {
+				os.Remove(target)
+			}
+			if tt.relative {
+				var err error
+				if target, err = filepath.Rel(filepath.Dir(link), target); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.Symlink(target, link); err != nil {
+				t.Skipf("can't create symlinks: %v", err)
+			}
+
+			if tt.dangling {
+				paths := []string{filepath.ToSlash(rel)}
+				err := createTarball(context.Background(), io.Discard, modelPath, paths, nil, false, false, true, false)
+				if err == nil {
+					t.Fatal("archived a dangling symlink without error")
+				}
+				return
+			}
+
+			// The link's target is archived as a regular file under the link's name
+			entries := readTestTar(t, saveTestTarball(t, modelPath, compressNone, "llama2:7b"))
+			entry, ok := entries[filepath.ToSlash(rel)]
+			if !ok {
+				t.Fatalf("%s missing from tarball", rel)
+			}
+			if entry.Header.Typeflag != tar.TypeReg {
+				t.Errorf("%s archived as type %q, want a regular file", rel, entry.Header.Typeflag)
+			}
+			if entry.Body != want[filepath.ToSlash(rel)] {
+				t.Errorf("%s archived as %q, want the target's content %q", rel, entry.Body, want[filepath.ToSlash(rel)])
+			}
+		})
+	}
+}
+
+func TestCheckFilesExist(t *testing.T) {
+	tests := []struct {
+		name    string
+		remove  []int
+		dangle  bool
+		missing int
+	}{
+		{name: "complete"},
+		{name: "one blob", remove: []int{0}, missing: 1},
+		{name: "every layer", remove: []int{0, 1, 2}, missing: 3},
+		{name: "dangling symlink", dangle: true, missing: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelPath := newTestStore(t)
+			manifest := addDefaultTestModel(t, modelPath, "llama2:7b")
+			modelName, _ := parseModelName("llama2:7b")
+			paths, err := getFilePaths(modelName, modelPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			removed := []string{}
+			for _, i := range tt.remove {
+				blob := filepath.Join("blobs", manifest.Layers[i].BlobName())
+				if err := os.Remove(filepath.Join(modelPath, blob)); err != nil {
+					t.Fatal(err)
+				}
+				removed = append(removed, blob)
+			}
+			if tt.dangle {
+				blob := filepath.Join("blobs", manifest.Layers[0].BlobName())
+				os.Remove(filepath.Join(modelPath, blob))
+				if err := os.Symlink(filepath.Join(t.TempDir(), "gone"), filepath.Join(modelPath, blob)); err != nil {
+					t.Skipf("can't create symlinks: %v", err)
+				}
+				removed = append(removed, blob)
+			}
+
+			err = checkFilesExist(modelPath, paths)
+			if tt.missing == 0 {
+				if err != nil {
+					t.Errorf("checkFilesExist: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("checkFilesExist reported nothing missing")
+			}
+			if want := fmt.Sprintf("%d blob(s) missing", tt.missing); !strings.Contains(err.Error(), want) {
+				t.Errorf("got %v, want %q", err, want)
+			}
+			for _, blob := range removed {
+				if !strings.Contains(err.Error(), blob) {
+					t.Errorf("error doesn't name %s: %v", blob, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveNoFollowSymlinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		relative bool
+	}{
+		{"absolute link", false},
+		{"relative link", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelPath := newTestStore(t)
+			manifest := addDefaultTestModel(t, modelPath, "llama2:7b")
+			modelName, _ := parseModelName("llama2:7b")
+			want := readStoreFiles(t, modelPath)
+
+			// Link the weights to a shared store
+			blob := filepath.Join("blobs", manifest.Layers[0].BlobName())
+			target := filepath.Join(t.TempDir(), manifest.Layers[0].BlobName())
+			if err := os.Rename(filepath.Join(modelPath, blob), target); err != nil {
+				t.Fatal(err)
+			}
+			if tt.relative {
+				var err error
+				if target, err = filepath.Rel(filepath.Join(modelPath, "blobs"), target); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.Symlink(target, filepath.Join(modelPath, blob)); err != nil {
+				t.Skipf("can't create symlinks: %v", err)
+			}
+
+			paths, err := getFilePaths(modelName, modelPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := createTarball(context.Background(), &buf, modelPath, paths, nil, false, false, false, false); err != nil {
+				t.Fatal(err)
+			}
+			tarball := filepath.Join(t.TempDir(), "links.tar")
+			if err := os.WriteFile(tarball, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			// The linked blob is archived as the link itself, everything else as content
+			for name, entry := range readTestTar(t, tarball) {
+				if name == filepath.ToSlash(blob) {
+					if entry.Header.Typeflag != tar.TypeSymlink || entry.Header.Linkname != target {
+						t.Errorf("%s archived as type %q -> %q, want a symlink to %q", name, entry.Header.Typeflag, entry.Header.Linkname, target)
+					}
+					continue
+				}
+				if entry.Header.Typeflag != tar.TypeReg || entry.Body != want[name] {
+					t.Errorf("%s archived as type %q with %q, want its content", name, entry.Header.Typeflag, entry.Body)
+				}
+			}
+
+			// load refuses the link, pointing at the flag that made it
+			err = extractTarball(context.Background(), tarball, newTestStore(t), testExtractOptions())
+			if err == nil || !strings.Contains(err.Error(), "--no-follow-symlinks") {
+				t.Errorf("load got %v, want a symlink error mentioning --no-follow-symlinks", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// firstPartSuffix ends the name of the first part of a split tarball
+const firstPartSuffix = ".001"
+
+// partName returns the file name of part n (starting at 1) of a split tarball
+func partName(base string, n int) string {
+	return fmt.Sprintf("%s.%03d", base, n)
+}
+
+// splitBase returns the name a split tarball's parts share when source names
+// its first part, such as backup.tar for backup.tar.001
+func splitBase(source string) (string, bool) {
+	if source == "-" || isURL(source) {
+		return "", false
+	}
+	return strings.CutSuffix(source, firstPartSuffix)
+}
+
+// parseSize parses a size such as 2GB, 500MiB, or 1048576. KB, MB, GB, and TB
+// are powers of 1000; KiB, MiB, GiB, and TiB, or just K, M, G, and T, are
+// powers of 1024. Sizes must come to at least one byte.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+		{"B", 1},
+	}
+	number, unit := s, int64(1)
+	for _, u := range units {
+		if trimmed, ok := strings.CutSuffix(s, u.suffix); ok {
+			number, unit = trimmed, u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 2GB, 500MiB, or a number of bytes)", s)
+	}
+	size := int64(n * float64(unit))
+	if size < 1 {
+		return 0, fmt.Errorf("invalid size %q (must be at least 1 byte)", s)
+	}
+	return size, nil
+}
+
+// splitWriter writes a stream across numbered files of at most size bytes
+// each, named base.001, base.002, and so on
+type splitWriter struct {
+	ctx     context.Context
+	base    string
+	size    int64
+	parts   int
+	cur     *os.File
+	written int64
+}
+
+// newSplitWriter returns a writer producing the parts of base. Existing parts
+// are an error unless force is set, in which case they're removed first so a
+// stale part from an earlier, longer save can't be read as part of this one.
+// Canceling ctx stops the writer before it starts another part.
+func newSplitWriter(ctx context.Context, base string, size int64, force bool) (*splitWriter, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid part size %d (must be at least 1 byte)", size)
+	}
+	existing, err := existingParts(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 && !force {
+		return nil, fmt.Errorf("output file %s already exists (use --force to overwrite)", existing[0])
+	}
+	for _, part := range existing {
+		if err := os.Remove(part); err != nil {
+			return nil, fmt.Errorf("failed to remove old part %s: %w", part, err)
+		}
+	}
+	return &splitWriter{ctx: ctx, base: base, size: size}, nil
+}
+
+func (w *splitWriter) Write(p []byte) (int, error) {
+	if w.size <= 0 {
+		return 0, fmt.Errorf("invalid part size %d (must be at least 1 byte)", w.size)
+	}
+	total := 0
+	for len(p) > 0 {
+		// Roll over to the next part at the size boundary
+		if w.cur == nil || w.written == w.size {
+			if err := w.next(); err != nil {
+				return total, err
+			}
+		}
+		chunk := p[:min(int64(len(p)), w.size-w.written)]
+		n, err := w.cur.Write(chunk)
+		total += n
+		w.written += int64(n)
+		if err != nil {
+			return total, fmt.Errorf("failed to write %s: %w", w.cur.Name(), err)
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
+
+// next closes the current part and creates the following one
+func (w *splitWriter) next() error {
+	if w.ctx.Err() != nil {
+		return contextError(w.ctx)
+	}
+	if w.cur != nil {
+		if err := w.cur.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", w.cur.Name(), err)
+		}
+	}
+	w.parts++
+	file, err := os.OpenFile(partName(w.base, w.parts), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	w.cur, w.written = file, 0
+	return nil
+}
+
+// Close finishes the last part. A stream that wrote nothing still gets an
+// empty first part, so there's always a file to load.
+func (w *splitWriter) Close() error {
+	if w.cur == nil {
+		if w.parts > 0 {
+			return nil
+		}
+		if err := w.next(); err != nil {
+			return err
+		}
+	}
+	err := w.cur.Close()
+	w.cur = nil
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", partName(w.base, w.parts), err)
+	}
+	return nil
+}
+
+// Remove deletes every part written so far, after a failed save
+func (w *splitWriter) Remove() {
+	for n := 1; n <= w.parts; n++ {
+		os.Remove(partName(w.base, n))
+	}
+}
+
+// existingParts returns the parts of base that exist, in order. Glob returns
+// cleaned paths, so they're matched against the cleaned base.
+func existingParts(base string) ([]string, error) {
+	matches, err := filepath.Glob(globEscape(base) + ".[0-9][0-9][0-9]*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parts of %s: %w", base, err)
+	}
+	parts := []string{}
+	for _, match := range matches {
+		if _, err := strconv.Atoi(strings.TrimPrefix(match, filepath.Clean(base)+".")); err == nil {
+			parts = append(parts, match)
+		}
+	}
+	return parts, nil
+}
+
+// globEscape escapes the glob metacharacters in a literal path
+func globEscape(path string) string {
+	replacer := strings.NewReplacer(`*`, `[*]`, `?`, `[?]`, `[`, `[[]`)
+	return replacer.Replace(path)
+}
+
+// openSplitParts opens the parts of a split tarball as one stream, returning
+// its total size. Every part but the last must be the same size, and a gap in
+// the numbering is reported as a missing part.
+func openSplitParts(base string) (io.ReadCloser, int64, error) {
+	readers := []io.Reader{}
+	files := multiCloser{}
+	var total, partSize, prevSize int64
+	n := 1
+	for ; ; n++ {
+		file, err := os.Open(partName(base, n))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			files.Close()
+			return nil, 0, fmt.Errorf("failed to open file: %w", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			files.Close()
+			return nil, 0, fmt.Errorf("failed to stat file: %w", err)
+		}
+		files = append(files, file)
+		readers = append(readers, file)
+
+		// Parts are filled in order, so only the last one may be short
+		if n == 1 {
+			partSize = info.Size()
+		} else if prevSize != partSize || info.Size() > partSize {
+			files.Close()
+			odd := partName(base, n-1)
+			if prevSize == partSize {
+				odd = file.Name()
+			}
+			return nil, 0, fmt.Errorf("part %s isn't the size of the other parts of %s (%s); the parts don't belong together", odd, base, formatBytes(partSize))
+		}
+		prevSize = info.Size()
+		total += info.Size()
+	}
+	if n == 1 {
+		return nil, 0, fmt.Errorf("failed to open file: %s does not exist", partName(base, 1))
+	}
+
+	// A later part past a gap means one in between is missing
+	existing, err := existingParts(base)
+	if err != nil {
+		files.Close()
+		return nil, 0, err
+	}
+	if len(existing) > n-1 {
+		files.Close()
+		return nil, 0, fmt.Errorf("part %s of split tarball %s is missing", partName(base, n), base)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(readers...), files}, total, nil
+}
+
+// multiCloser closes several files together
+type multiCloser []*os.File
+
+func (m multiCloser) Close() error {
+	for _, file := range m {
+		file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testLayer is the media type and content of a layer written by addTestModel
+type testLayer struct {
+	MediaType string
+	Data      string
+}
+
+// newTestStore returns an empty models directory with manifests/ and blobs/
+// under t.TempDir()
+func newTestStore(t testing.TB) string {
+	t.Helper()
+	modelPath := t.TempDir()
+	for _, dir := range []string{"manifests", "blobs"} {
+		if err := os.MkdirAll(filepath.Join(modelPath, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return modelPath
+}
+
+// writeTestBlob stores data as a content-addressed blob and returns its layer
+func writeTestBlob(t testing.TB, modelPath, mediaType string, data []byte) Layer {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	layer := Layer{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(sum[:]), Size: int64(len(data))}
+	if err := os.WriteFile(filepath.Join(modelPath, "blobs", layer.BlobName()), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return layer
+}
+
+// addTestModel writes a model named name to the store: a config blob, a blob
+// for each layer, and a manifest referencing them. It returns the manifest.
+func addTestModel(t testing.TB, modelPath, name string, layers ...testLayer) *Manifest {
+	t.Helper()
+	modelName, err := parseModelName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := writeTestBlob(t, modelPath, configMediaType, []byte(`{"model_format":"gguf","model_family":"llama"}`))
+	manifest := &Manifest{SchemaVersion: manifestSchemaVersion, MediaType: manifestMediaType, Config: config, Layers: []Layer{}}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, writeTestBlob(t, modelPath, layer.MediaType, []byte(layer.Data)))
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeManifest(modelPath, modelName, data, true); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+// addDefaultTestModel writes a model with model, template, and params layers
+func addDefaultTestModel(t *testing.T, modelPath, name string) *Manifest {
+	t.Helper()
+	return addTestModel(t, modelPath, name,
+		testLayer{modelMediaType, "GGUF weights of " + name},
+		testLayer{templateMediaType, "{{ .Prompt }}"},
+		testLayer{paramsMediaType, `{"stop":["</s>"]}`},
+	)
+}
+
+// readStoreFiles returns the content of every file under modelPath by its
+// slash-separated path relative to modelPath
+func readStoreFiles(t *testing.T, modelPath string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(modelPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(modelPath, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
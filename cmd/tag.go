@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag SOURCE TARGET",
+	Short: "Add a tag to a model",
+	Long: `Add a tag to an Ollama model by writing a copy of its manifest under the
+new tag. The new tag shares all of the model's blobs.
+
+Unlike copy, tag only changes the tag: the target must name the same model
+(host, namespace, and model name) as the source. Use --allow-rename to tag it
+under a different name anyway.
+
+Examples:
+  ollie tag llama2:latest llama2:v1
+  ollie tag myorg/mistral:7b myorg/mistral:stable --force`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Parse model names
+		source, err := parseModelName(args[0])
+		if err != nil {
+			return err
+		}
+		target, err := parseModelName(args[1])
+		if err != nil {
+			return err
+		}
+
+		if !tagAllowRename && (source.Host != target.Host || source.Namespace != target.Namespace || source.Model != target.Model) {
+			return fmt.Errorf("%s and %s are different models; tag only changes the tag (use --allow-rename or copy)", source.ShortString(), target.ShortString())
+		}
+		if source.String() == target.String() {
+			return fmt.Errorf("%s already has tag %s", source.ShortString(), target.Tag)
+		}
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Keep other ollie processes from modifying the models directory meanwhile
+		unlock, err := lockModelsDir(modelPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if _, err := copyModelManifest(modelPath, source, target, tagForce); err != nil {
+			return err
+		}
+
+		slog.Info("Tagged model", "source", source.ShortString(), "tag", target.ShortString())
+		return nil
+	},
+}
+
+var (
+	tagForce       bool
+	tagAllowRename bool
+)
+
+func init() {
+	tagCmd.Flags().BoolVarP(&tagForce, "force", "f", false, "move the tag if it already exists")
+	tagCmd.Flags().BoolVar(&tagAllowRename, "allow-rename", false, "allow the target to name a different model")
+	rootCmd.AddCommand(tagCmd)
+}
@@ -1,60 +1,158 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
-const systemPath = "/usr/share/ollama/.ollama/models"
+// defaultModelsPaths returns the locations checked, in order, when no models path
+// is configured: the system-wide install location, ~/.ollama/models,
+// $XDG_DATA_HOME/ollama/models (~/.local/share when unset), and the path used by
+// the official Docker image. Locations that don't apply on a platform are skipped.
+func defaultModelsPaths(home string) []string {
+	paths := []string{}
+	if systemPath != "" {
+		paths = append(paths, systemPath)
+	}
+	paths = append(paths, filepath.Join(home, ".ollama", "models"))
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	paths = append(paths, filepath.Join(dataHome, "ollama", "models"))
+
+	if dockerPath != "" {
+		paths = append(paths, dockerPath)
+	}
+	return paths
+}
+
+// configuredModelsPath returns the explicitly configured models directory and
+// where it came from: the --models-path flag, then the OLLIE_MODELS environment
+// variable (which lets ollie use a different store than the server), then
+// OLLAMA_MODELS. The path is empty when none of them is set.
+func configuredModelsPath() (string, string) {
+	if modelsPathFlag != "" {
+		return modelsPathFlag, "--models-path"
+	}
+	for _, env := range []string{"OLLIE_MODELS", "OLLAMA_MODELS"} {
+		if value := os.Getenv(env); value != "" {
+			return value, env
+		}
+	}
+	return "", ""
+}
 
 // getOllamaModelsPath returns the path to the Ollama models directory.
-// It first checks the OLLAMA_MODELS environment variable.
-// If not set, it defaults to /usr/share/ollama/.ollama, falling back to ~/.ollama/models if not found.
+// It uses configuredModelsPath when set, returning an error if that path isn't
+// an existing directory. Otherwise it returns the first of defaultModelsPaths
+// that exists, falling back to ~/.ollama/models (%USERPROFILE%\.ollama\models
+// on Windows).
 func getOllamaModelsPath() (string, error) {
-	modelPath := os.Getenv("OLLAMA_MODELS")
-	if modelPath == "" {
-		if _, err := os.Stat(systemPath); err == nil {
-			modelPath = systemPath
-		} else {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return "", fmt.Errorf("failed to get home directory: %w", err)
+	modelPath, source := configuredModelsPath()
+
+	if modelPath != "" {
+		info, err := os.Stat(modelPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("%s is set to %s, which does not exist", source, modelPath)
+			}
+			return "", fmt.Errorf("failed to access %s directory %s: %w", source, modelPath, err)
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("%s is set to %s, which is not a directory", source, modelPath)
+		}
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		modelPath = filepath.Join(home, ".ollama", "models")
+		for _, candidate := range defaultModelsPaths(home) {
+			if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+				modelPath = candidate
+				break
 			}
-			modelPath = filepath.Join(home, ".ollama", "models")
 		}
 	}
-	slog.Info("Using Ollama models path", "path", modelPath)
+	slog.Debug("Using Ollama models path", "path", modelPath)
 	return modelPath, nil
 }
 
-// getOllamaUIDGID looks up the ollama user and group and returns their UID and GID.
-// If the ollama user or group is not found, it returns -1 for both (indicating no chown should occur).
-func getOllamaUIDGID() (int, int, error) {
-	ollamaUser, err := user.Lookup("ollama")
-	if err != nil {
-		// If ollama user doesn't exist, don't change ownership
+// resolveOwner converts an ownership spec into a UID and GID for extracted files.
+// An empty spec uses the ollama user and group, "none" disables chown (-1, -1),
+// and "user:group" accepts either names or numeric IDs for each part.
+func resolveOwner(spec string) (int, int, error) {
+	switch spec {
+	case "":
+		return getOllamaUIDGID()
+	case "none":
 		return -1, -1, nil
 	}
 
-	ollamaGroup, err := user.LookupGroup("ollama")
-	if err != nil {
-		// If ollama group doesn't exist, don't change ownership
-		return -1, -1, nil
+	userPart, groupPart, ok := strings.Cut(spec, ":")
+	if !ok || userPart == "" || groupPart == "" {
+		return -1, -1, fmt.Errorf("invalid owner %q: expected user:group, uid:gid, or none", spec)
 	}
 
-	uid, err := strconv.Atoi(ollamaUser.Uid)
+	uid, err := strconv.Atoi(userPart)
 	if err != nil {
-		return -1, -1, fmt.Errorf("failed to parse UID: %w", err)
+		u, err := user.Lookup(userPart)
+		if err != nil {
+			return -1, -1, fmt.Errorf("failed to look up user %s: %w", userPart, err)
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return -1, -1, fmt.Errorf("failed to parse UID: %w", err)
+		}
 	}
 
-	gid, err := strconv.Atoi(ollamaGroup.Gid)
+	gid, err := strconv.Atoi(groupPart)
 	if err != nil {
-		return -1, -1, fmt.Errorf("failed to parse GID: %w", err)
+		g, err := user.LookupGroup(groupPart)
+		if err != nil {
+			return -1, -1, fmt.Errorf("failed to look up group %s: %w", groupPart, err)
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return -1, -1, fmt.Errorf("failed to parse GID: %w", err)
+		}
 	}
 
 	return uid, gid, nil
 }
+
+// parseNumericOwner parses a UID:GID spec of non-negative numeric IDs. Unlike
+// resolveOwner, it never looks up users or groups, so it works where they
+// don't exist, such as in minimal container images.
+func parseNumericOwner(spec string) (int, int, error) {
+	userPart, groupPart, ok := strings.Cut(spec, ":")
+	uid, uidErr := strconv.Atoi(userPart)
+	gid, gidErr := strconv.Atoi(groupPart)
+	if !ok || uidErr != nil || gidErr != nil || uid < 0 || gid < 0 {
+		return -1, -1, fmt.Errorf("invalid --numeric-owner %q: expected UID:GID as non-negative numbers", spec)
+	}
+	return uid, gid, nil
+}
+
+// fileSHA256 returns the hex-encoded sha256 digest of the file at path
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// clearModelsPathConfig unsets --models-path, OLLIE_MODELS, and OLLAMA_MODELS
+// for the rest of the test
+func clearModelsPathConfig(t *testing.T) {
+	t.Helper()
+	saved := modelsPathFlag
+	t.Cleanup(func() { modelsPathFlag = saved })
+	modelsPathFlag = ""
+	t.Setenv("OLLIE_MODELS", "")
+	t.Setenv("OLLAMA_MODELS", "")
+}
+
+func TestGetOllamaModelsPathConfigured(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "models.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"directory", dir, ""},
+		{"missing", filepath.Join(dir, "missing"), "does not exist"},
+		{"file", file, "is not a directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearModelsPathConfig(t)
+			t.Setenv("OLLAMA_MODELS", tt.path)
+
+			got, err := getOllamaModelsPath()
+			if tt.wantErr == "" {
+				if err != nil || got != tt.path {
+					t.Errorf("getOllamaModelsPath() = %q, %v; want %q", got, err, tt.path)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "OLLAMA_MODELS") {
+				t.Errorf("getOllamaModelsPath() = %q, %v; want an OLLAMA_MODELS error containing %q", got, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// setTestHome points os.UserHomeDir at home for the rest of the test
+func setTestHome(t *testing.T, home string) {
+	t.Helper()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+func TestDefaultModelsPaths(t *testing.T) {
+	home := filepath.Join("home", "user")
+	tests := []struct {
+		name     string
+		dataHome string
+		wantData string
+	}{
+		{"XDG_DATA_HOME unset", "", filepath.Join(home, ".local", "share", "ollama", "models")},
+		{"XDG_DATA_HOME set", "data", filepath.Join("data", "ollama", "models")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", tt.dataHome)
+			want := []string{}
+			if systemPath != "" {
+				want = append(want, systemPath)
+			}
+			want = append(want, filepath.Join(home, ".ollama", "models"), tt.wantData)
+			if dockerPath != "" {
+				want = append(want, dockerPath)
+			}
+			if got := defaultModelsPaths(home); !slices.Equal(got, want) {
+				t.Errorf("defaultModelsPaths(%q) = %q, want %q", home, got, want)
+			}
+		})
+	}
+}
+
+func TestGetOllamaModelsPathDefaults(t *testing.T) {
+	for _, path := range []string{systemPath, dockerPath} {
+		if _, err := os.Stat(path); path != "" && err == nil {
+			t.Skipf("%s exists on this system and would be found first", path)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing []string
+		files    []string
+		dataHome string
+		want     string
+	}{
+		{"nothing exists", nil, nil, "", ".ollama/models"},
+		{"home", []string{".ollama/models"}, nil, "", ".ollama/models"},
+		{"home before XDG", []string{".ollama/models", ".local/share/ollama/models"}, nil, "", ".ollama/models"},
+		{"XDG default", []string{".local/share/ollama/models"}, nil, "", ".local/share/ollama/models"},
+		{"XDG_DATA_HOME", []string{"data/ollama/models", ".local/share/ollama/models"}, nil, "data", "data/ollama/models"},
+		{"XDG_DATA_HOME missing", []string{".local/share/ollama/models"}, nil, "data", ".ollama/models"},
+		{"home is a file", []string{".ollama", ".local/share/ollama/models"}, []string{".ollama/models"}, "", ".local/share/ollama/models"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearModelsPathConfig(t)
+			home := t.TempDir()
+			setTestHome(t, home)
+			if tt.dataHome != "" {
+				t.Setenv("XDG_DATA_HOME", filepath.Join(home, tt.dataHome))
+			} else {
+				t.Setenv("XDG_DATA_HOME", "")
+			}
+			for _, dir := range tt.existing {
+				if err := os.MkdirAll(filepath.Join(home, dir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A file where a directory would be isn't a models directory
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(home, file), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := getOllamaModelsPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(home, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("getOllamaModelsPath() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestConfiguredModelsPathPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		ollie      string
+		ollama     string
+		wantPath   string
+		wantSource string
+	}{
+		{"nothing set", "", "", "", "", ""},
+		{"OLLAMA_MODELS", "", "", "/ollama", "/ollama", "OLLAMA_MODELS"},
+		{"OLLIE_MODELS", "", "/ollie", "", "/ollie", "OLLIE_MODELS"},
+		{"OLLIE_MODELS over OLLAMA_MODELS", "", "/ollie", "/ollama", "/ollie", "OLLIE_MODELS"},
+		{"flag", "/flag", "", "", "/flag", "--models-path"},
+		{"flag over both", "/flag", "/ollie", "/ollama", "/flag", "--models-path"},
+		{"flag over OLLAMA_MODELS", "/flag", "", "/ollama", "/flag", "--models-path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearModelsPathConfig(t)
+			modelsPathFlag = tt.flag
+			t.Setenv("OLLIE_MODELS", tt.ollie)
+			t.Setenv("OLLAMA_MODELS", tt.ollama)
+
+			path, source := configuredModelsPath()
+			if path != tt.wantPath || source != tt.wantSource {
+				t.Errorf("configuredModelsPath() = %q, %q; want %q, %q", path, source, tt.wantPath, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestGetOllamaModelsPathPrecedence(t *testing.T) {
+	// Only the winning path exists, so a wrong pick fails with "does not exist"
+	clearModelsPathConfig(t)
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	t.Setenv("OLLAMA_MODELS", missing)
+	t.Setenv("OLLIE_MODELS", dir)
+	if got, err := getOllamaModelsPath(); err != nil || got != dir {
+		t.Errorf("with OLLIE_MODELS set, getOllamaModelsPath() = %q, %v; want %q", got, err, dir)
+	}
+
+	modelsPathFlag = missing
+	if _, err := getOllamaModelsPath(); err == nil || !strings.Contains(err.Error(), "--models-path") {
+		t.Errorf("with --models-path set, got %v, want a --models-path error", err)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// systemPath is where the Linux installer stores models for the ollama service user
+const systemPath = "/usr/share/ollama/.ollama/models"
+
+// dockerPath is where the official Ollama Docker image stores models
+const dockerPath = "/root/.ollama/models"
+
+// oNoFollow makes os.OpenFile fail on a symlink instead of following it
+const oNoFollow = syscall.O_NOFOLLOW
+
+// getOllamaUIDGID looks up the ollama user and group and returns their UID and GID.
+// If the ollama user or group is not found, it returns -1 for both (indicating no chown should occur).
+func getOllamaUIDGID() (int, int, error) {
+	ollamaUser, err := user.Lookup("ollama")
+	if err != nil {
+		// If ollama user doesn't exist, don't change ownership
+		return -1, -1, nil
+	}
+
+	ollamaGroup, err := user.LookupGroup("ollama")
+	if err != nil {
+		// If ollama group doesn't exist, don't change ownership
+		return -1, -1, nil
+	}
+
+	uid, err := strconv.Atoi(ollamaUser.Uid)
+	if err != nil {
+		return -1, -1, fmt.Errorf("failed to parse UID: %w", err)
+	}
+
+	gid, err := strconv.Atoi(ollamaGroup.Gid)
+	if err != nil {
+		return -1, -1, fmt.Errorf("failed to parse GID: %w", err)
+	}
+
+	return uid, gid, nil
+}
+
+// chown changes the ownership of path to uid and gid
+func chown(path string, uid, gid int) error {
+	return os.Chown(path, uid, gid)
+}
+
+// freeSpace returns the bytes available to unprivileged users on the filesystem holding path
+func freeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// fileOwner returns the UID and GID that own the file described by info
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
+
+// tryLockFile takes an exclusive flock on file without blocking, reporting
+// false if another process holds it
+func tryLockFile(file *os.File) (bool, error) {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
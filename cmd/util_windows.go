@@ -0,0 +1,73 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// systemPath is empty on Windows, where Ollama only stores models per user,
+// so getOllamaModelsPath always falls back to the home directory
+const systemPath = ""
+
+// dockerPath is empty on Windows, where the Linux Docker image's path doesn't apply
+const dockerPath = ""
+
+// oNoFollow is zero on Windows, which has no O_NOFOLLOW
+const oNoFollow = 0
+
+// getOllamaUIDGID always returns -1 for both IDs on Windows, which has no
+// ollama service user, so no ownership changes are made.
+func getOllamaUIDGID() (int, int, error) {
+	return -1, -1, nil
+}
+
+// chown is a no-op on Windows, where file ownership isn't managed via UID/GID
+func chown(path string, uid, gid int) error {
+	return nil
+}
+
+var procGetDiskFreeSpaceExW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// freeSpace returns the bytes available to the current user on the volume holding path
+func freeSpace(path string) (int64, error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if r, _, err := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&available)), 0, 0); r == 0 {
+		return 0, err
+	}
+	return int64(available), nil
+}
+
+// fileOwner never reports an owner on Windows, where ownership isn't a UID/GID
+func fileOwner(info os.FileInfo) (int, int, bool) {
+	return -1, -1, false
+}
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+// Flags and errors used with LockFileEx
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+// tryLockFile takes an exclusive lock on the first byte of file without
+// blocking, reporting false if another process holds it
+func tryLockFile(file *os.File) (bool, error) {
+	var overlapped syscall.Overlapped
+	r, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if r != 0 {
+		return true, nil
+	}
+	if err == errorLockViolation {
+		return false, nil
+	}
+	return false, err
+}
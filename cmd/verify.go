@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ollie/pkg/ollama"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyModel checks every blob referenced by a model's manifest, returning the
+// blobs that are missing from disk and those whose content doesn't match their digest
+func verifyModel(modelPath string, modelName *ModelName) (missing, mismatched []string, err error) {
+	layers, err := parseManifest(filepath.Join(modelPath, manifestRelPath(modelName)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, layer := range layers {
+		name := layer.BlobName()
+		blobPath := filepath.Join(modelPath, "blobs", name)
+
+		actual, err := fileSHA256(blobPath)
+		if os.IsNotExist(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash blob %s: %w", name, err)
+		}
+		if actual != strings.TrimPrefix(name, "sha256-") {
+			mismatched = append(mismatched, name)
+		}
+	}
+
+	return missing, mismatched, nil
+}
+
+// nonCanonicalDigests returns the digests in a model's manifest that aren't in
+// the canonical sha256:<lowercase hex> form, along with the manifest data with
+// them rewritten
+func nonCanonicalDigests(modelPath string, modelName *ModelName) ([]string, []byte, error) {
+	data, err := os.ReadFile(filepath.Join(modelPath, manifestRelPath(modelName)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	canonical, changed, err := ollama.CanonicalizeManifest(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changed, canonical, nil
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [MODEL_NAME]",
+	Short: "Check the integrity of local models",
+	Long: `Verify an Ollama model by checking that every blob referenced by its manifest
+exists on disk and that each blob's content hashes to the digest in its name.
+Missing blobs and digest mismatches are reported separately, and the command
+exits with a non-zero status if any model fails.
+
+Digests written with uppercase hex or a missing or differently-cased sha256:
+prefix are reported too, since Ollama may not find their blobs. Use --repair to
+rewrite such manifests to the canonical sha256:<lowercase hex> form. Variant
+manifests of an index are blobs and aren't rewritten.
+
+Use --all to verify every local model, except those matched by .ollieignore
+(see "ollie list --help"). A model named on the command line is always
+verified, even if .ollieignore matches it.
+
+Examples:
+  ollie verify llama2
+  ollie verify --all
+  ollie verify --all --repair`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if verifyAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: completeFirstModelName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Verification failures aren't usage errors
+		cmd.SilenceUsage = true
+
+		// Get model path from environment or use default
+		modelPath, err := getOllamaModelsPath()
+		if err != nil {
+			return err
+		}
+
+		// Rewriting manifests must not race other ollie processes
+		if verifyRepair {
+			unlock, err := lockModelsDir(modelPath)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+		}
+
+		var models []*ModelName
+		if verifyAll {
+			if models, err = listBulkModels(modelPath); err != nil {
+				return err
+			}
+		} else {
+			modelName, err := parseModelName(args[0])
+			if err != nil {
+				return err
+			}
+			models = []*ModelName{modelName}
+		}
+
+		failed := 0
+		for _, modelName := range models {
+			missing, mismatched, err := verifyModel(modelPath, modelName)
+			if err != nil {
+				fmt.Printf("FAIL %s: %v\n", modelName.ShortString(), err)
+				failed++
+				continue
+			}
+			nonCanonical, canonical, err := nonCanonicalDigests(modelPath, modelName)
+			if err != nil {
+				fmt.Printf("FAIL %s: %v\n", modelName.ShortString(), err)
+				failed++
+				continue
+			}
+			repaired := false
+			if verifyRepair && len(nonCanonical) > 0 {
+				if err := writeManifest(modelPath, modelName, canonical, true); err != nil {
+					fmt.Printf("FAIL %s: %v\n", modelName.ShortString(), err)
+					failed++
+					continue
+				}
+				repaired = true
+			}
+			if len(missing) == 0 && len(mismatched) == 0 && (len(nonCanonical) == 0 || repaired) {
+				fmt.Printf("OK   %s\n", modelName.ShortString())
+				for _, digest := range nonCanonical {
+					fmt.Printf("  repaired: %s -> %s\n", digest, ollama.NormalizeDigest(digest))
+				}
+				continue
+			}
+
+			failed++
+			fmt.Printf("FAIL %s\n", modelName.ShortString())
+			for _, name := range missing {
+				fmt.Printf("  missing:  %s\n", name)
+			}
+			for _, name := range mismatched {
+				fmt.Printf("  mismatch: %s\n", name)
+			}
+			for _, digest := range nonCanonical {
+				if repaired {
+					fmt.Printf("  repaired: %s -> %s\n", digest, ollama.NormalizeDigest(digest))
+				} else {
+					fmt.Printf("  digest:   %s is not canonical (use --repair)\n", digest)
+				}
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d model(s) failed verification", failed, len(models))
+		}
+		return nil
+	},
+}
+
+var (
+	verifyAll    bool
+	verifyRepair bool
+)
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "verify every local model")
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "rewrite manifest digests that aren't in canonical sha256:<lowercase hex> form")
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNonCanonicalDigests(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite func(digest string) string
+		changed bool
+	}{
+		{"canonical", func(digest string) string { return digest }, false},
+		{"upper case hex", func(digest string) string { return "sha256:" + strings.ToUpper(strings.TrimPrefix(digest, "sha256:")) }, true},
+		{"upper case prefix", func(digest string) string { return "SHA256:" + strings.TrimPrefix(digest, "sha256:") }, true},
+		{"no prefix", func(digest string) string { return strings.TrimPrefix(digest, "sha256:") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelPath := newTestStore(t)
+			manifest := addDefaultTestModel(t, modelPath, "llama2:7b")
+			modelName, _ := parseModelName("llama2:7b")
+
+			// Rewrite the weights layer's digest in the manifest as written
+			weights := manifest.Layers[0].Digest
+			data := readStoreFiles(t, modelPath)[filepath.ToSlash(manifestRelPath(modelName))]
+			malformed := tt.rewrite(weights)
+			if err := writeManifest(modelPath, modelName, []byte(strings.ReplaceAll(data, weights, malformed)), true); err != nil {
+				t.Fatal(err)
+			}
+
+			changed, canonical, err := nonCanonicalDigests(modelPath, modelName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{}
+			if tt.changed {
+				want = []string{malformed}
+			}
+			if !slices.Equal(changed, want) {
+				t.Errorf("nonCanonicalDigests = %q, want %q", changed, want)
+			}
+			// The repaired manifest is the original one
+			if string(canonical) != data {
+				t.Errorf("repaired manifest = %s, want %s", canonical, data)
+			}
+		})
+	}
+}
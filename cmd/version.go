@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// versionInfo describes the ollie build
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// buildInfo returns the version and build metadata of the running binary
+func buildInfo() versionInfo {
+	return versionInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Print ollie's version along with the commit and date it was built from, the
+Go version it was built with, and the platform it runs on. Include this in bug
+reports. Use --json for machine-readable output, e.g. to check compatibility
+in scripts; "ollie --version" prints just the version.
+
+The commit and date are set at build time (see build.sh) and are "unknown" for
+a plain go build.
+
+Examples:
+  ollie version
+  ollie version --json | jq -r .version`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := buildInfo()
+		if versionJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+
+		fmt.Printf("Version:     %s\n", info.Version)
+		fmt.Printf("Commit:      %s\n", info.Commit)
+		fmt.Printf("Built:       %s\n", info.Date)
+		fmt.Printf("Go version:  %s\n", info.GoVersion)
+		fmt.Printf("Platform:    %s\n", info.Platform)
+		return nil
+	},
+}
+
+var versionJSON bool
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "print the version and build information as JSON")
+	rootCmd.AddCommand(versionCmd)
+}
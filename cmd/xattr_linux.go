@@ -0,0 +1,57 @@
+//go:build linux
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// readXattrs returns the extended attributes of the file at path
+func readXattrs(path string) (map[string]string, error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list xattrs of %s: %w", path, err)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	if size, err = unix.Listxattr(path, buf); err != nil {
+		return nil, fmt.Errorf("failed to list xattrs of %s: %w", path, err)
+	}
+
+	attrs := map[string]string{}
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		valueSize, err := unix.Getxattr(path, string(name), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read xattr %s of %s: %w", name, path, err)
+		}
+		value := make([]byte, valueSize)
+		if valueSize, err = unix.Getxattr(path, string(name), value); err != nil {
+			return nil, fmt.Errorf("failed to read xattr %s of %s: %w", name, path, err)
+		}
+		attrs[string(name)] = string(value[:valueSize])
+	}
+	return attrs, nil
+}
+
+// writeXattrs sets the xattrs recorded in a tar header's PAX records on path
+func writeXattrs(path string, records map[string]string) error {
+	for key, value := range records {
+		name, ok := strings.CutPrefix(key, paxXattrPrefix)
+		if !ok {
+			continue
+		}
+		if err := unix.Setxattr(path, name, []byte(value), 0); err != nil {
+			return fmt.Errorf("failed to set xattr %s: %w", name, err)
+		}
+	}
+	return nil
+}
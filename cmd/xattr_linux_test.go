@@ -0,0 +1,70 @@
+//go:build linux
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestXattrRoundTrip(t *testing.T) {
+	const name, value = "user.ollie.test", "kept"
+
+	src := newTestStore(t)
+	manifest := addDefaultTestModel(t, src, "llama2:7b")
+	blob := filepath.Join("blobs", manifest.Layers[0].BlobName())
+	if err := unix.Setxattr(filepath.Join(src, blob), name, []byte(value), 0); err != nil {
+		t.Skipf("user xattrs aren't supported here: %v", err)
+	}
+
+	modelName, _ := parseModelName("llama2:7b")
+	paths, err := getFilePaths(modelName, src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := createTarball(context.Background(), &buf, src, paths, nil, false, true, true, false); err != nil {
+		t.Fatal(err)
+	}
+	tarball := filepath.Join(t.TempDir(), "xattrs.tar")
+	if err := os.WriteFile(tarball, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recorded as a PAX record of the blob's entry
+	entry := readTestTar(t, tarball)[filepath.ToSlash(blob)]
+	if entry.Header == nil || entry.Header.PAXRecords[paxXattrPrefix+name] != value {
+		t.Fatalf("%s wasn't recorded in the tarball", name)
+	}
+
+	tests := []struct {
+		name     string
+		preserve bool
+	}{
+		{"preserved", true},
+		{"dropped", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := newTestStore(t)
+			opts := testExtractOptions()
+			opts.PreserveXattrs = tt.preserve
+			if err := extractTarball(context.Background(), tarball, dest, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			attrs, err := readXattrs(filepath.Join(dest, blob))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, ok := attrs[name]; ok != tt.preserve || (ok && got != value) {
+				t.Errorf("%s after load = %q (present %v), want present %v", name, got, ok, tt.preserve)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package cmd
+
+// readXattrs returns no attributes outside Linux, where --preserve-xattrs isn't supported
+func readXattrs(path string) (map[string]string, error) {
+	return nil, nil
+}
+
+// writeXattrs does nothing outside Linux, where --preserve-xattrs isn't supported
+func writeXattrs(path string, records map[string]string) error {
+	return nil
+}
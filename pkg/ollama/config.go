@@ -0,0 +1,36 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ModelConfig holds the fields Ollama records in a model's config blob that
+// describe the model itself. Fields missing from the blob are left empty.
+type ModelConfig struct {
+	ModelFormat   string   `json:"model_format"`
+	ModelFamily   string   `json:"model_family"`
+	ModelFamilies []string `json:"model_families"`
+	// ModelType is the parameter count, such as 7B or 13B
+	ModelType string `json:"model_type"`
+	// FileType is the quantization, such as Q4_0 or F16
+	FileType     string `json:"file_type"`
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// ReadConfigBlob reads the config blob a manifest references from the models
+// directory, returning its raw JSON along with the decoded model fields
+func ReadConfigBlob(modelPath string, config Layer) ([]byte, *ModelConfig, error) {
+	data, err := os.ReadFile(filepath.Join(modelPath, "blobs", config.BlobName()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config blob: %w", err)
+	}
+	var modelConfig ModelConfig
+	if err := json.Unmarshal(data, &modelConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config blob %s: %w", config.Digest, err)
+	}
+	return data, &modelConfig, nil
+}
@@ -0,0 +1,95 @@
+package ollama
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RefNameAnnotation is the OCI annotation naming an index entry, such as a tag
+const RefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// Platform describes what an index entry was built for
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// IndexEntry is one variant listed by an index manifest. Its own manifest is
+// stored as a blob named by its digest.
+type IndexEntry struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Layer returns the entry as a blob reference to the variant's manifest
+func (e IndexEntry) Layer() Layer {
+	return Layer{MediaType: e.MediaType, Digest: e.Digest, Size: e.Size}
+}
+
+// Name returns the name used to select the entry: its platform variant (such
+// as a quantization), else its ref.name annotation, else its os/architecture
+func (e IndexEntry) Name() string {
+	if e.Platform != nil && e.Platform.Variant != "" {
+		return e.Platform.Variant
+	}
+	if name := e.Annotations[RefNameAnnotation]; name != "" {
+		return name
+	}
+	if e.Platform != nil && e.Platform.Architecture != "" {
+		return e.Platform.OS + "/" + e.Platform.Architecture
+	}
+	return strings.TrimPrefix(e.Digest, "sha256:")
+}
+
+// SelectVariant picks the entry of an index named variant. An empty variant
+// selects the only entry, and is ambiguous when there are several.
+func SelectVariant(index *Manifest, variant string) (*IndexEntry, error) {
+	names := []string{}
+	for i, entry := range index.Manifests {
+		if variant != "" && entry.Name() == variant {
+			return &index.Manifests[i], nil
+		}
+		names = append(names, entry.Name())
+	}
+
+	switch {
+	case len(index.Manifests) == 0:
+		return nil, fmt.Errorf("%w: index lists no variants", ErrManifestCorrupt)
+	case variant != "":
+		return nil, fmt.Errorf("no variant %q (available: %s)", variant, strings.Join(names, ", "))
+	case len(index.Manifests) == 1:
+		return &index.Manifests[0], nil
+	default:
+		return nil, fmt.Errorf("multiple variants, choose one of: %s", strings.Join(names, ", "))
+	}
+}
+
+// ReadVariantManifest reads the manifest an index entry points to from the
+// blobs directory of the models directory at modelPath
+func ReadVariantManifest(modelPath string, entry IndexEntry) (*Manifest, error) {
+	blobPath := filepath.Join(modelPath, "blobs", entry.Layer().BlobName())
+	data, err := os.ReadFile(blobPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: variant manifest %s is missing", ErrModelNotFound, blobPath)
+		}
+		return nil, fmt.Errorf("failed to read variant manifest: %w", err)
+	}
+
+	manifest, err := decodeManifest(blobPath, data)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.IsIndex() {
+		return nil, fmt.Errorf("%w: %s: nested indexes aren't supported", ErrManifestCorrupt, blobPath)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,409 @@
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sentinel errors returned when reading manifests
+var (
+	// ErrModelNotFound indicates the requested model has no manifest locally
+	ErrModelNotFound = errors.New("model not found")
+	// ErrManifestCorrupt indicates a manifest exists but couldn't be parsed
+	ErrManifestCorrupt = errors.New("manifest is corrupt")
+)
+
+// Layer describes a blob referenced by a manifest, either its config or one of its layers
+type Layer struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// BlobName returns the file name of the layer's blob in the blobs directory
+func (l Layer) BlobName() string {
+	return BlobFileName(l.Digest)
+}
+
+// ManifestSchemaVersion is the only manifest schema version Ollama writes
+const ManifestSchemaVersion = 2
+
+// Media types Ollama uses for manifests, configs, and layers
+const (
+	ManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ConfigMediaType   = "application/vnd.docker.container.image.v1+json"
+	ModelMediaType    = "application/vnd.ollama.image.model"
+	TemplateMediaType = "application/vnd.ollama.image.template"
+	SystemMediaType   = "application/vnd.ollama.image.system"
+	ParamsMediaType   = "application/vnd.ollama.image.params"
+	LicenseMediaType  = "application/vnd.ollama.image.license"
+)
+
+// Media types of manifests that list per-platform or per-quantization variants
+// instead of layers
+const (
+	IndexMediaType        = "application/vnd.oci.image.index.v1+json"
+	ManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// Manifest represents the structure of an Ollama manifest file
+type Manifest struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	MediaType     string  `json:"mediaType"`
+	Config        Layer   `json:"config"`
+	Layers        []Layer `json:"layers"`
+	// Manifests lists the variants of an index manifest, whose content is
+	// stored as blobs. It is empty for ordinary manifests.
+	Manifests []IndexEntry `json:"manifests,omitempty"`
+}
+
+// IsIndex reports whether the manifest is an index of variants rather than a
+// single model
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == IndexMediaType || m.MediaType == ManifestListMediaType
+}
+
+// cachedManifest is a decoded manifest along with the file state it was read from
+type cachedManifest struct {
+	modTime  time.Time
+	size     int64
+	manifest Manifest
+}
+
+// manifestCache holds decoded manifests by path so that walking every model more
+// than once in a process, as gc and verify do, parses each manifest only once.
+// Entries are reused only while the file's mtime and size are unchanged.
+var manifestCache = struct {
+	sync.Mutex
+	entries map[string]cachedManifest
+}{entries: map[string]cachedManifest{}}
+
+// copyManifest returns a deep copy of m, so callers can modify its layers and
+// index entries without changing the cached manifest
+func copyManifest(m Manifest) *Manifest {
+	m.Layers = slices.Clone(m.Layers)
+	m.Manifests = slices.Clone(m.Manifests)
+	for i := range m.Manifests {
+		entry := &m.Manifests[i]
+		if entry.Platform != nil {
+			platform := *entry.Platform
+			entry.Platform = &platform
+		}
+		entry.Annotations = maps.Clone(entry.Annotations)
+	}
+	return &m
+}
+
+// ReadManifest reads and decodes the manifest file at path
+func ReadManifest(path string) (*Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no manifest at %s", ErrModelNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	// A tag that's a directory, e.g. in a store laid out by hand,
+	// would otherwise fail with a confusing read error
+	if info.IsDir() {
+		return nil, fmt.Errorf("expected manifest file, found directory: %s", path)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("expected manifest file, found %s: %s", info.Mode().Type(), path)
+	}
+
+	manifestCache.Lock()
+	cached, ok := manifestCache.entries[path]
+	manifestCache.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return copyManifest(cached.manifest), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no manifest at %s", ErrModelNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest, err := decodeManifest(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestCache.Lock()
+	manifestCache.entries[path] = cachedManifest{modTime: info.ModTime(), size: info.Size(), manifest: manifest}
+	manifestCache.Unlock()
+
+	return copyManifest(manifest), nil
+}
+
+// decodeManifest decodes the manifest data read from path, rejecting JSON that
+// isn't an Ollama manifest, and normalizes its digests
+func decodeManifest(path string, data []byte) (Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %s: %w", ErrManifestCorrupt, path, err)
+	}
+
+	// Reject JSON that isn't an Ollama manifest rather than treating it as empty
+	if manifest.SchemaVersion != ManifestSchemaVersion {
+		return Manifest{}, fmt.Errorf("%w: %s: unsupported schemaVersion %d (expected %d)", ErrManifestCorrupt, path, manifest.SchemaVersion, ManifestSchemaVersion)
+	}
+	if manifest.MediaType != ManifestMediaType && !manifest.IsIndex() {
+		return Manifest{}, fmt.Errorf("%w: %s: unexpected mediaType %q (expected %q)", ErrManifestCorrupt, path, manifest.MediaType, ManifestMediaType)
+	}
+	manifest.normalizeDigests()
+	return manifest, nil
+}
+
+// BlobFileName converts a manifest digest (sha256:<hex>) to its blob file name
+// (sha256-<hex>). The digest is normalized first, so SHA256:<HEX> and a bare
+// hex digest name the same blob.
+func BlobFileName(digest string) string {
+	return "sha256-" + strings.TrimPrefix(NormalizeDigest(digest), "sha256:")
+}
+
+// NormalizeDigest returns digest in the canonical sha256:<lowercase hex> form
+// Ollama writes, accepting any casing of the sha256: prefix and of the hex, or
+// no prefix at all. Digests of other algorithms are returned unchanged.
+func NormalizeDigest(digest string) string {
+	digest = strings.TrimSpace(digest)
+	if len(digest) >= len("sha256:") && strings.EqualFold(digest[:len("sha256:")], "sha256:") {
+		digest = digest[len("sha256:"):]
+	} else if strings.Contains(digest, ":") {
+		return digest
+	}
+	return "sha256:" + strings.ToLower(digest)
+}
+
+// digests returns pointers to every digest the manifest references: its
+// config, its layers, and the variants of an index
+func (m *Manifest) digests() []*string {
+	digests := []*string{}
+	if m.Config.Digest != "" {
+		digests = append(digests, &m.Config.Digest)
+	}
+	for i := range m.Layers {
+		if m.Layers[i].Digest != "" {
+			digests = append(digests, &m.Layers[i].Digest)
+		}
+	}
+	for i := range m.Manifests {
+		digests = append(digests, &m.Manifests[i].Digest)
+	}
+	return digests
+}
+
+// normalizeDigests rewrites every digest in the manifest to canonical form
+func (m *Manifest) normalizeDigests() {
+	for _, digest := range m.digests() {
+		*digest = NormalizeDigest(*digest)
+	}
+}
+
+// CanonicalizeManifest rewrites the digests in raw manifest data that aren't in
+// canonical form, returning the new data and the digests that were rewritten.
+// Only the digest strings change, so every other field is kept as written.
+func CanonicalizeManifest(data []byte) ([]byte, []string, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrManifestCorrupt, err)
+	}
+
+	changed := []string{}
+	for _, digest := range manifest.digests() {
+		canonical := NormalizeDigest(*digest)
+		if canonical == *digest || slices.Contains(changed, *digest) {
+			continue
+		}
+		old, _ := json.Marshal(*digest)
+		replacement, _ := json.Marshal(canonical)
+		data = bytes.ReplaceAll(data, old, replacement)
+		changed = append(changed, *digest)
+	}
+	return data, changed, nil
+}
+
+// ParseManifest reads and parses the manifest file, returning the config
+// followed by each layer. For an index, every variant's manifest blob is
+// returned along with its config and layers.
+func ParseManifest(path string) ([]Layer, error) {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// An index references every variant's manifest and, through it, its layers
+	if manifest.IsIndex() {
+		modelPath, _, err := ModelFromManifestPath(path)
+		if err != nil {
+			return nil, err
+		}
+		layers := []Layer{}
+		for _, entry := range manifest.Manifests {
+			variant, err := ReadVariantManifest(modelPath, entry)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, entry.Layer())
+			layers = append(layers, variant.blobLayers()...)
+		}
+		return layers, nil
+	}
+
+	return manifest.blobLayers(), nil
+}
+
+// blobLayers returns the manifest's config followed by each layer, skipping
+// entries without a digest
+func (m *Manifest) blobLayers() []Layer {
+	layers := []Layer{}
+
+	// Add config
+	if m.Config.Digest != "" {
+		layers = append(layers, m.Config)
+	}
+
+	// Add layers
+	for _, layer := range m.Layers {
+		if layer.Digest != "" {
+			layers = append(layers, layer)
+		}
+	}
+
+	return layers
+}
+
+// ManifestRelPath returns the manifest path of a model relative to the models directory
+func ManifestRelPath(modelName *ModelName) string {
+	return filepath.Join(
+		"manifests",
+		modelName.Host,
+		modelName.Namespace,
+		modelName.Model,
+		modelName.Tag,
+	)
+}
+
+// ModelFromManifestPath derives the models directory and model name from the path
+// of a manifest file laid out as <models>/manifests/host/namespace/model/tag
+func ModelFromManifestPath(path string) (string, *ModelName, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve manifest path: %w", err)
+	}
+
+	tagDir, tag := filepath.Split(absPath)
+	modelDir, model := filepath.Split(filepath.Clean(tagDir))
+	namespaceDir, namespace := filepath.Split(filepath.Clean(modelDir))
+	hostDir, host := filepath.Split(filepath.Clean(namespaceDir))
+	manifestsDir := filepath.Clean(hostDir)
+
+	if filepath.Base(manifestsDir) != "manifests" || tag == "" || model == "" || namespace == "" || host == "" {
+		return "", nil, fmt.Errorf("%s is not inside a manifests/host/namespace/model/tag layout", path)
+	}
+
+	return filepath.Dir(manifestsDir), &ModelName{
+		Host:      host,
+		Namespace: namespace,
+		Model:     model,
+		Tag:       tag,
+	}, nil
+}
+
+// FilePaths returns the paths of a model's manifest and all of its blobs,
+// relative to the models directory at modelPath.
+// When keep is non-nil, only blobs of layers it returns true for are included.
+func FilePaths(modelName *ModelName, modelPath string, keep func(Layer) bool) ([]string, error) {
+	manifestPath := filepath.Join(modelPath, ManifestRelPath(modelName))
+
+	manifest, err := ReadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+
+	// Add manifest path (relative)
+	paths = append(paths, ManifestRelPath(modelName))
+
+	// Follow the selected variant of an index, keeping its manifest blob
+	if manifest.IsIndex() {
+		entry, err := SelectVariant(manifest, modelName.Variant)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", modelName.ShortString(), err)
+		}
+		if manifest, err = ReadVariantManifest(modelPath, *entry); err != nil {
+			return nil, err
+		}
+		paths = append(paths, filepath.Join("blobs", entry.Layer().BlobName()))
+	} else if modelName.Variant != "" {
+		return nil, fmt.Errorf("%s has no variants, but variant %q was requested", modelName.ShortString(), modelName.Variant)
+	}
+	layers := manifest.blobLayers()
+
+	// Add blob paths
+	for _, layer := range layers {
+		if keep != nil && !keep(layer) {
+			continue
+		}
+		paths = append(paths, filepath.Join("blobs", layer.BlobName()))
+	}
+
+	return paths, nil
+}
+
+// ListModels walks the manifests tree and returns every locally available model
+func ListModels(modelPath string) ([]*ModelName, error) {
+	manifestsDir := filepath.Join(modelPath, "manifests")
+	models := []*ModelName{}
+
+	// A fresh models directory has no manifests yet
+	if _, err := os.Stat(manifestsDir); os.IsNotExist(err) {
+		return models, nil
+	}
+
+	err := filepath.WalkDir(manifestsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		// Manifests are stored as manifests/host/namespace/model/tag
+		rel, err := filepath.Rel(manifestsDir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 4 {
+			return nil
+		}
+
+		models = append(models, &ModelName{
+			Host:      parts[0],
+			Namespace: parts[1],
+			Model:     parts[2],
+			Tag:       parts[3],
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	return models, nil
+}
@@ -0,0 +1,261 @@
+package ollama
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTestManifest writes data as a manifest file under t.TempDir() and
+// returns its path
+func writeTestManifest(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "manifests", "registry.ollama.ai", "library", "llama2", "7b")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadManifestValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		ok   bool
+	}{
+		{"valid", `{"schemaVersion":2,"mediaType":"` + ManifestMediaType + `","config":{"digest":"sha256:aa"},"layers":[]}`, true},
+		{"index", `{"schemaVersion":2,"mediaType":"` + IndexMediaType + `","manifests":[]}`, true},
+		{"manifest list", `{"schemaVersion":2,"mediaType":"` + ManifestListMediaType + `","manifests":[]}`, true},
+		{"not json", `not a manifest`, false},
+		{"truncated", `{"schemaVersion":2,"mediaType":`, false},
+		{"empty", ``, false},
+		{"empty object", `{}`, false},
+		{"null", `null`, false},
+		{"array", `[]`, false},
+		{"other json", `{"name":"llama2","version":"7b"}`, false},
+		{"schema version 1", `{"schemaVersion":1,"mediaType":"` + ManifestMediaType + `"}`, false},
+		{"schema version string", `{"schemaVersion":"2","mediaType":"` + ManifestMediaType + `"}`, false},
+		{"wrong media type", `{"schemaVersion":2,"mediaType":"application/json"}`, false},
+		{"missing media type", `{"schemaVersion":2,"layers":[]}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := ReadManifest(writeTestManifest(t, tt.data))
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("ReadManifest: %v", err)
+				}
+				if manifest.SchemaVersion != ManifestSchemaVersion {
+					t.Errorf("schemaVersion = %d, want %d", manifest.SchemaVersion, ManifestSchemaVersion)
+				}
+				return
+			}
+			if !errors.Is(err, ErrManifestCorrupt) {
+				t.Errorf("ReadManifest = %+v, %v; want ErrManifestCorrupt", manifest, err)
+			}
+		})
+	}
+}
+
+func TestReadManifestNotFound(t *testing.T) {
+	_, err := ReadManifest(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("got %v, want ErrModelNotFound", err)
+	}
+}
+
+func TestNormalizeDigest(t *testing.T) {
+	hex := strings.Repeat("ab", 32)
+	tests := []struct {
+		digest string
+		want   string
+	}{
+		{"sha256:" + hex, "sha256:" + hex},
+		{"SHA256:" + hex, "sha256:" + hex},
+		{"Sha256:" + strings.ToUpper(hex), "sha256:" + hex},
+		{"sha256:" + strings.ToUpper(hex), "sha256:" + hex},
+		{hex, "sha256:" + hex},
+		{strings.ToUpper(hex), "sha256:" + hex},
+		{"  sha256:" + hex + "\n", "sha256:" + hex},
+		{"sha512:ABC", "sha512:ABC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.digest, func(t *testing.T) {
+			if got := NormalizeDigest(tt.digest); got != tt.want {
+				t.Errorf("NormalizeDigest(%q) = %q, want %q", tt.digest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlobFileName(t *testing.T) {
+	hex := strings.Repeat("0f", 32)
+	for _, digest := range []string{"sha256:" + hex, "SHA256:" + strings.ToUpper(hex), hex} {
+		if got, want := BlobFileName(digest), "sha256-"+hex; got != want {
+			t.Errorf("BlobFileName(%q) = %q, want %q", digest, got, want)
+		}
+	}
+}
+
+func TestCanonicalizeManifest(t *testing.T) {
+	hex := strings.Repeat("cd", 32)
+	tests := []struct {
+		name        string
+		data        string
+		wantData    string
+		wantChanged []string
+		wantErr     bool
+	}{
+		{
+			name:     "canonical",
+			data:     `{"config":{"digest":"sha256:` + hex + `"},"layers":[]}`,
+			wantData: `{"config":{"digest":"sha256:` + hex + `"},"layers":[]}`,
+		},
+		{
+			name:        "upper case",
+			data:        `{"config":{"digest":"SHA256:` + strings.ToUpper(hex) + `"},"layers":[{"digest":"SHA256:` + strings.ToUpper(hex) + `","size":1}]}`,
+			wantData:    `{"config":{"digest":"sha256:` + hex + `"},"layers":[{"digest":"sha256:` + hex + `","size":1}]}`,
+			wantChanged: []string{"SHA256:" + strings.ToUpper(hex)},
+		},
+		{
+			name:        "missing prefix, other fields kept as written",
+			data:        `{ "layers": [ {"digest": "` + hex + `", "x-extra": true} ] }`,
+			wantData:    `{ "layers": [ {"digest": "sha256:` + hex + `", "x-extra": true} ] }`,
+			wantChanged: []string{hex},
+		},
+		{name: "not json", data: `{"config":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, changed, err := CanonicalizeManifest([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrManifestCorrupt) {
+					t.Errorf("got %v, want ErrManifestCorrupt", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantData {
+				t.Errorf("data = %s, want %s", data, tt.wantData)
+			}
+			if !slices.Equal(changed, tt.wantChanged) {
+				t.Errorf("changed = %q, want %q", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestReadManifestNormalizesDigests(t *testing.T) {
+	hex := strings.Repeat("ef", 32)
+	path := writeTestManifest(t, `{"schemaVersion":2,"mediaType":"`+ManifestMediaType+`","config":{"digest":"SHA256:`+strings.ToUpper(hex)+`"},"layers":[{"digest":"`+hex+`"}]}`)
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Config.Digest != "sha256:"+hex || manifest.Layers[0].Digest != "sha256:"+hex {
+		t.Errorf("digests = %q, %q; want sha256:%s", manifest.Config.Digest, manifest.Layers[0].Digest, hex)
+	}
+}
+
+func TestReadManifestCopiesIndexEntries(t *testing.T) {
+	path := writeTestManifest(t, `{"schemaVersion":2,"mediaType":"`+IndexMediaType+`","manifests":[{"digest":"sha256:aa","platform":{"architecture":"amd64","os":"linux","variant":"q4_0"},"annotations":{"`+RefNameAnnotation+`":"q4_0"}}]}`)
+	first, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Manifests[0].Digest = "sha256:bb"
+	first.Manifests[0].Platform.Variant = "changed"
+	first.Manifests[0].Annotations[RefNameAnnotation] = "changed"
+
+	second, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := second.Manifests[0]
+	if entry.Digest != "sha256:aa" || entry.Platform.Variant != "q4_0" || entry.Annotations[RefNameAnnotation] != "q4_0" {
+		t.Errorf("changing a returned manifest changed the cached one: %+v, platform %+v", entry, *entry.Platform)
+	}
+}
+
+// BenchmarkReadManifest reads 200 manifests with and without the cache
+func BenchmarkReadManifest(b *testing.B) {
+	dir := b.TempDir()
+	layer := `{"mediaType":"application/vnd.ollama.image.model","digest":"sha256:` + strings.Repeat("ab", 32) + `","size":1}`
+	data := `{"schemaVersion":2,"mediaType":"` + ManifestMediaType + `","config":{"digest":"sha256:` + strings.Repeat("cd", 32) + `"},"layers":[` + strings.Repeat(layer+",", 4) + layer + `]}`
+	paths := []string{}
+	for i := range 200 {
+		path := filepath.Join(dir, fmt.Sprintf("manifest-%d", i))
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			b.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			for b.Loop() {
+				if !cached {
+					manifestCache.Lock()
+					clear(manifestCache.entries)
+					manifestCache.Unlock()
+				}
+				for _, path := range paths {
+					if _, err := ReadManifest(path); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadVariantManifest(t *testing.T) {
+	hex := strings.Repeat("ef", 32)
+	tests := []struct {
+		name string
+		data string
+		ok   bool
+	}{
+		{"valid", `{"schemaVersion":2,"mediaType":"` + ManifestMediaType + `","config":{"digest":"SHA256:` + strings.ToUpper(hex) + `"},"layers":[{"digest":"` + hex + `"}]}`, true},
+		{"nested index", `{"schemaVersion":2,"mediaType":"` + IndexMediaType + `","manifests":[]}`, false},
+		{"not json", `not a manifest`, false},
+		{"empty object", `{}`, false},
+		{"schema version 1", `{"schemaVersion":1,"mediaType":"` + ManifestMediaType + `"}`, false},
+		{"wrong media type", `{"schemaVersion":2,"mediaType":"application/json"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelPath := t.TempDir()
+			entry := IndexEntry{MediaType: ManifestMediaType, Digest: "sha256:" + strings.Repeat("12", 32)}
+			if err := os.MkdirAll(filepath.Join(modelPath, "blobs"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(modelPath, "blobs", entry.Layer().BlobName()), []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			manifest, err := ReadVariantManifest(modelPath, entry)
+			if !tt.ok {
+				if !errors.Is(err, ErrManifestCorrupt) {
+					t.Errorf("ReadVariantManifest = %+v, %v; want ErrManifestCorrupt", manifest, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if manifest.Config.Digest != "sha256:"+hex || manifest.Layers[0].Digest != "sha256:"+hex {
+				t.Errorf("digests = %q, %q; want sha256:%s", manifest.Config.Digest, manifest.Layers[0].Digest, hex)
+			}
+		})
+	}
+}
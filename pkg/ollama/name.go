@@ -0,0 +1,135 @@
+// Package ollama resolves Ollama model names to the manifests and blobs that
+// make them up in a local models directory, without talking to the Ollama server.
+package ollama
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Allowed characters in each part of a model name, following Ollama's rules:
+// parts start with a letter or digit, namespaces can't contain dots, and only
+// the host may contain a port
+var (
+	hostPattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,349}(:[0-9]+)?$`)
+	namespacePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,79}$`)
+	modelPattern     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,79}$`)
+	tagPattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,79}$`)
+)
+
+// ModelName represents the parsed components of an Ollama model name
+type ModelName struct {
+	Host      string
+	Namespace string
+	Model     string
+	Tag       string
+	// Digest optionally pins a specific manifest by its sha256 digest (or a prefix of it)
+	Digest string
+	// Variant selects one entry of an index manifest by name, such as a
+	// quantization like q4_0. It is unused for ordinary manifests.
+	Variant string
+}
+
+// String returns the fully qualified host/namespace/model:tag form of the name
+func (m *ModelName) String() string {
+	return fmt.Sprintf("%s/%s/%s:%s", m.Host, m.Namespace, m.Model, m.Tag)
+}
+
+// ShortString returns the name the way Ollama displays it, omitting the default
+// registry host and library namespace
+func (m *ModelName) ShortString() string {
+	name := m.Model + ":" + m.Tag
+	if m.Host != "registry.ollama.ai" {
+		return m.Host + "/" + m.Namespace + "/" + name
+	}
+	if m.Namespace != "library" {
+		return m.Namespace + "/" + name
+	}
+	return name
+}
+
+// ParseModelName parses an Ollama model name into its components
+// Supports formats:
+//   - host/namespace/model:tag
+//   - namespace/model:tag
+//   - namespace/model
+//   - model:tag
+//   - model
+//
+// Any of these may be followed by @sha256:<digest> to pin a specific manifest.
+// The host may include a port (e.g. localhost:5000/library/model:tag). A colon
+// is only treated as the tag separator when no slash follows it, and a two-part
+// name whose first part has a port (e.g. localhost:5000/model) is read as host/model.
+func ParseModelName(name string) (*ModelName, error) {
+	result := &ModelName{
+		Host:      "registry.ollama.ai",
+		Namespace: "library",
+		Model:     "",
+		Tag:       "latest",
+	}
+
+	// Split off a pinned manifest digest
+	rest := name
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		digest := rest[i+1:]
+		if !strings.HasPrefix(digest, "sha256:") || len(digest) == len("sha256:") {
+			return nil, fmt.Errorf("invalid digest in model name: %s (expected @sha256:<hex>)", name)
+		}
+		result.Digest = digest
+		rest = rest[:i]
+	}
+
+	// Split off the tag, ignoring colons that belong to a host:port
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		result.Tag = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, "/")
+	switch len(parts) {
+	case 1:
+		result.Model = parts[0]
+	case 2:
+		if strings.Contains(parts[0], ":") {
+			result.Host = parts[0]
+		} else {
+			result.Namespace = parts[0]
+		}
+		result.Model = parts[1]
+	case 3:
+		result.Host = parts[0]
+		result.Namespace = parts[1]
+		result.Model = parts[2]
+	default:
+		return nil, fmt.Errorf("invalid model name format: %s", name)
+	}
+
+	if err := result.validate(); err != nil {
+		return nil, fmt.Errorf("invalid model name %s: %w", name, err)
+	}
+
+	return result, nil
+}
+
+// validate checks each part of the name against the characters Ollama allows,
+// reporting the first invalid part
+func (m *ModelName) validate() error {
+	parts := []struct {
+		kind    string
+		value   string
+		pattern *regexp.Regexp
+		allowed string
+	}{
+		{"host", m.Host, hostPattern, "letters, digits, '.', '-', '_', and an optional :port"},
+		{"namespace", m.Namespace, namespacePattern, "up to 80 letters, digits, '-', and '_'"},
+		{"model", m.Model, modelPattern, "up to 80 letters, digits, '.', '-', and '_'"},
+		{"tag", m.Tag, tagPattern, "up to 80 letters, digits, '.', '-', and '_'"},
+	}
+	for _, part := range parts {
+		if !part.pattern.MatchString(part.value) {
+			return fmt.Errorf("invalid %s %q (must start with a letter or digit and contain only %s)", part.kind, part.value, part.allowed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseModelNameHostPort(t *testing.T) {
+	tests := []struct {
+		name string
+		want ModelName
+	}{
+		{"model:tag", ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "model", Tag: "tag"}},
+		{"model", ModelName{Host: "registry.ollama.ai", Namespace: "library", Model: "model", Tag: "latest"}},
+		{"myorg/model:v1", ModelName{Host: "registry.ollama.ai", Namespace: "myorg", Model: "model", Tag: "v1"}},
+		{"localhost:5000/foo/bar:latest", ModelName{Host: "localhost:5000", Namespace: "foo", Model: "bar", Tag: "latest"}},
+		{"localhost:5000/foo/bar", ModelName{Host: "localhost:5000", Namespace: "foo", Model: "bar", Tag: "latest"}},
+		{"localhost:5000/bar", ModelName{Host: "localhost:5000", Namespace: "library", Model: "bar", Tag: "latest"}},
+		{"localhost:5000/bar:v2", ModelName{Host: "localhost:5000", Namespace: "library", Model: "bar", Tag: "v2"}},
+		{"registry.example.com:443/team/model:q4_0", ModelName{Host: "registry.example.com:443", Namespace: "team", Model: "model", Tag: "q4_0"}},
+		{"localhost:5000/foo/bar:v1@sha256:abc", ModelName{Host: "localhost:5000", Namespace: "foo", Model: "bar", Tag: "v1", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseModelName(tt.name)
+			if err != nil {
+				t.Fatalf("ParseModelName(%q): %v", tt.name, err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseModelName(%q) = %+v, want %+v", tt.name, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelNameShortStringHostPort(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"model:tag", "model:tag"},
+		{"myorg/model:v1", "myorg/model:v1"},
+		{"localhost:5000/bar", "localhost:5000/library/bar:latest"},
+		{"localhost:5000/foo/bar:v1", "localhost:5000/foo/bar:v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseModelName(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if short := got.ShortString(); short != tt.want {
+				t.Errorf("ShortString() = %q, want %q", short, tt.want)
+			}
+			// The short form parses back to the same model
+			again, err := ParseModelName(got.ShortString())
+			if err != nil || again.String() != got.String() {
+				t.Errorf("ParseModelName(%q) = %v, %v; want %s", got.ShortString(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestParseModelNameValidation(t *testing.T) {
+	long := func(n int) string { return strings.Repeat("a", n) }
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"llama2", ""},
+		{"llama2:7b-q4_0", ""},
+		{"llama3.1:8b", ""},
+		{"my-org/my_model:v1.0", ""},
+		{"1model:2tag", ""},
+		{"host.example.com/ns/model:tag", ""},
+		{"localhost:5000/ns/model:tag", ""},
+		{long(80) + ":" + long(80), ""},
+		{"", `invalid model "`},
+		{":tag", `invalid model "`},
+		{"model:", `invalid tag "`},
+		{"-model", `invalid model "`},
+		{".model", `invalid model "`},
+		{"model name", `invalid model "`},
+		{"model/", `invalid model "`},
+		{"model:t@g", "invalid digest"},
+		{"model:-tag", `invalid tag "`},
+		{"model:tag!", `invalid tag "`},
+		{"my.org/model", `invalid namespace "`},
+		{"_org/model", `invalid namespace "`},
+		{"ho st/ns/model", `invalid host "`},
+		{"host:port/ns/model", `invalid host "`},
+		{long(81), `invalid model "`},
+		{"model:" + long(81), `invalid tag "`},
+		{long(81) + "/model", `invalid namespace "`},
+		{"a/b/c/d", "invalid model name format"},
+		{"model@sha256:", "invalid digest"},
+		{"model@md5:abc", "invalid digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseModelName(tt.name)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseModelName(%q): %v", tt.name, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseModelName(%q) = %v, want an error containing %q", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}